@@ -0,0 +1,133 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+var (
+	ErrProcessNotStarted = errors.New("process not started")
+	ErrOutputAlreadySet  = errors.New("output already set")
+)
+
+// cmdAdapter wraps an *exec.Cmd to implement the processCmd interface.
+type cmdAdapter struct {
+	*exec.Cmd
+	noNewPrivileges bool
+	init            *Init
+	audit           *auditRun
+	// pipeWriters are the write ends of the pipes created by
+	// StdoutPipe and StderrPipe, closed once the command started.
+	pipeWriters *[]*os.File
+}
+
+func (c cmdAdapter) Start() (err error) {
+	if c.audit != nil {
+		c.audit.startTime = time.Now()
+	}
+
+	if c.init != nil {
+		err = c.init.start(c)
+	} else {
+		err = c.start()
+	}
+
+	for _, writer := range *c.pipeWriters {
+		_ = writer.Close()
+	}
+	*c.pipeWriters = nil
+
+	if err != nil && c.audit != nil {
+		c.audit.emit(c.Cmd, -1, err)
+	}
+	return err
+}
+
+func (c cmdAdapter) start() error {
+	if c.noNewPrivileges {
+		return startWithNoNewPrivileges(c.Cmd)
+	}
+	return c.Cmd.Start()
+}
+
+// StdoutPipe returns a pipe connected to the stdout of the command.
+// Unlike exec.Cmd.StdoutPipe, Wait does not close the read end of the
+// pipe, so the output can be drained concurrently with Wait.
+func (c cmdAdapter) StdoutPipe() (io.ReadCloser, error) {
+	return c.pipe(&c.Stdout, "stdout")
+}
+
+// StderrPipe returns a pipe connected to the stderr of the command,
+// see StdoutPipe.
+func (c cmdAdapter) StderrPipe() (io.ReadCloser, error) {
+	return c.pipe(&c.Stderr, "stderr")
+}
+
+func (c cmdAdapter) pipe(output *io.Writer, name string) (io.ReadCloser, error) {
+	if *output != nil {
+		return nil, fmt.Errorf("%w: %s", ErrOutputAlreadySet, name)
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating %s pipe: %w", name, err)
+	}
+	*output = writer
+	*c.pipeWriters = append(*c.pipeWriters, writer)
+	return reader, nil
+}
+
+func (c cmdAdapter) Wait() error {
+	err := c.Cmd.Wait()
+	if c.init != nil {
+		c.init.unregister(c.PID())
+	}
+	if c.audit != nil {
+		c.audit.emit(c.Cmd, exitCode(c.ProcessState, err), err)
+	}
+	return err
+}
+
+func (c cmdAdapter) CombinedOutput() ([]byte, error) {
+	if c.init == nil && !c.noNewPrivileges && c.audit == nil {
+		return c.Cmd.CombinedOutput()
+	}
+
+	var buffer bytes.Buffer
+	c.SetOutput(&buffer)
+	err := c.Start()
+	if err != nil {
+		return nil, err
+	}
+	err = c.Wait()
+	return buffer.Bytes(), err
+}
+
+func (c cmdAdapter) PID() int {
+	if c.Process == nil {
+		return 0
+	}
+	return c.Process.Pid
+}
+
+func (c cmdAdapter) Signal(signal os.Signal) error {
+	if c.Process == nil {
+		return ErrProcessNotStarted
+	}
+	return c.Process.Signal(signal)
+}
+
+func (c cmdAdapter) State() *os.ProcessState {
+	return c.ProcessState
+}
+
+// SetOutput sets both the stdout and stderr of the command to
+// the writer given, such that they share a single pipe.
+func (c cmdAdapter) SetOutput(writer io.Writer) {
+	c.Stdout = writer
+	c.Stderr = writer
+}
@@ -0,0 +1,167 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// AuditRecord is a structured record of a command execution.
+type AuditRecord struct {
+	// Path is the path of the command executable.
+	Path string
+	// Args are the command arguments, including the command name,
+	// with the redaction rules applied.
+	Args []string
+	// Dir is the working directory of the command.
+	Dir string
+	// EnvKeys are the names of the environment variables of the command.
+	// The environment variable values are never recorded.
+	EnvKeys []string
+	// UID is the user ID the command runs as.
+	UID int
+	// GID is the group ID the command runs as.
+	GID int
+	// StartTime is the time the command was started at.
+	StartTime time.Time
+	// Duration is the duration of the command execution.
+	Duration time.Duration
+	// ExitCode is the exit code of the command, or -1 if the command
+	// failed to start or did not exit normally.
+	ExitCode int
+	// Err is the error starting or waiting for the command.
+	Err error
+}
+
+// AuditSettings are the redaction settings for the Audit option.
+type AuditSettings struct {
+	// RedactFlags are flag names, such as "--password" or "-p", whose
+	// value is redacted, either as the next argument or after an
+	// equal sign in the same argument.
+	RedactFlags []string
+	// RedactRegexes are regular expressions whose matches
+	// in each argument are redacted.
+	RedactRegexes []*regexp.Regexp
+	// RedactEnv are names of environment variables of the command
+	// whose values are redacted if found in the arguments.
+	RedactEnv []string
+	// Placeholder replaces the redacted values,
+	// and defaults to "[REDACTED]".
+	Placeholder string
+}
+
+func (s *AuditSettings) setDefaults() {
+	if s.Placeholder == "" {
+		s.Placeholder = "[REDACTED]"
+	}
+}
+
+// Audit calls the hook given with an audit record once each command
+// exits or fails to start, with the arguments redacted using the
+// settings given. The hook is called synchronously and should not block.
+func Audit(hook func(record AuditRecord), settings AuditSettings) OptionSetter {
+	settings.setDefaults()
+	return func(o *options) {
+		o.audit = &auditOptions{
+			hook:     hook,
+			settings: settings,
+		}
+	}
+}
+
+type auditOptions struct {
+	hook     func(record AuditRecord)
+	settings AuditSettings
+}
+
+// auditRun contains the audit state of a single command execution.
+type auditRun struct {
+	auditOptions
+	uid       int
+	gid       int
+	startTime time.Time
+}
+
+func newAuditRun(options options) *auditRun {
+	run := &auditRun{
+		auditOptions: *options.audit,
+		uid:          os.Getuid(),
+		gid:          os.Getgid(),
+	}
+	if options.credential != nil {
+		run.uid = int(options.credential.uid)
+		run.gid = int(options.credential.gid)
+	}
+	return run
+}
+
+func (a *auditRun) emit(cmd *exec.Cmd, exitCode int, err error) {
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	record := AuditRecord{
+		Path:      cmd.Path,
+		Args:      a.settings.redact(cmd.Args, env),
+		Dir:       cmd.Dir,
+		EnvKeys:   make([]string, len(env)),
+		UID:       a.uid,
+		GID:       a.gid,
+		StartTime: a.startTime,
+		Duration:  time.Since(a.startTime),
+		ExitCode:  exitCode,
+		Err:       err,
+	}
+
+	if record.Dir == "" {
+		record.Dir, _ = os.Getwd()
+	}
+
+	for i, keyValue := range env {
+		record.EnvKeys[i], _, _ = strings.Cut(keyValue, "=")
+	}
+
+	a.hook(record)
+}
+
+func (s *AuditSettings) redact(args, env []string) (redacted []string) {
+	redacted = make([]string, len(args))
+	copy(redacted, args)
+
+	for i := 0; i < len(redacted); i++ {
+		for _, flag := range s.RedactFlags {
+			if redacted[i] == flag && i+1 < len(redacted) {
+				i++
+				redacted[i] = s.Placeholder
+				break
+			} else if strings.HasPrefix(redacted[i], flag+"=") {
+				redacted[i] = flag + "=" + s.Placeholder
+				break
+			}
+		}
+	}
+
+	envValues := make([]string, 0, len(s.RedactEnv))
+	for _, keyValue := range env {
+		key, value, _ := strings.Cut(keyValue, "=")
+		for _, name := range s.RedactEnv {
+			if key == name && value != "" {
+				envValues = append(envValues, value)
+			}
+		}
+	}
+
+	for i := range redacted {
+		for _, value := range envValues {
+			redacted[i] = strings.ReplaceAll(redacted[i], value, s.Placeholder)
+		}
+		for _, regex := range s.RedactRegexes {
+			redacted[i] = regex.ReplaceAllLiteralString(redacted[i], s.Placeholder)
+		}
+	}
+
+	return redacted
+}
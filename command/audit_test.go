@@ -0,0 +1,112 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AuditSettings_redact(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		settings AuditSettings
+		args     []string
+		env      []string
+		redacted []string
+	}{
+		"no rule": {
+			args:     []string{"cmd", "--password", "secret"},
+			redacted: []string{"cmd", "--password", "secret"},
+		},
+		"flags": {
+			settings: AuditSettings{RedactFlags: []string{"--password", "-p"}},
+			args: []string{"cmd", "--password", "secret", "--password=secret2",
+				"-p", "secret3", "--passwordx=y", "-p"},
+			redacted: []string{"cmd", "--password", "X", "--password=X",
+				"-p", "X", "--passwordx=y", "-p"},
+		},
+		"regex": {
+			settings: AuditSettings{RedactRegexes: []*regexp.Regexp{
+				regexp.MustCompile(`token=[^&]+`),
+			}},
+			args:     []string{"curl", "https://x/?token=abc&a=b"},
+			redacted: []string{"curl", "https://x/?X&a=b"},
+		},
+		"env values": {
+			settings: AuditSettings{RedactEnv: []string{"API_KEY", "EMPTY"}},
+			args:     []string{"curl", "-H", "Authorization: Bearer k3y", ""},
+			env:      []string{"API_KEY=k3y", "EMPTY=", "OTHER=Bearer"},
+			redacted: []string{"curl", "-H", "Authorization: Bearer X", ""},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			settings := testCase.settings
+			settings.Placeholder = "X"
+
+			redacted := settings.redact(testCase.args, testCase.env)
+
+			assert.Equal(t, testCase.redacted, redacted)
+		})
+	}
+}
+
+func Test_Cmder_Audit(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	var records []AuditRecord
+	hook := func(record AuditRecord) {
+		records = append(records, record)
+	}
+	cmder := NewWithDefaults(Audit(hook, AuditSettings{RedactFlags: []string{"--password"}}))
+
+	dir := t.TempDir()
+	cmd := exec.Command("sh", "-c", "exit 4", "sh", "--password", "secret")
+	cmd.Env = []string{"A=1", "B=2"}
+	cmd.Dir = dir
+	_, err := cmder.RunWithOptions(cmd)
+	require.Error(t, err)
+
+	_, err = cmder.Run(exec.Command("/non/existent"))
+	require.Error(t, err)
+
+	require.Len(t, records, 2)
+
+	record := records[0]
+	assert.Positive(t, record.Duration)
+	assert.WithinDuration(t, time.Now(), record.StartTime, time.Minute)
+	assert.EqualError(t, record.Err, "exit status 4")
+	record.Duration = 0
+	record.StartTime = time.Time{}
+	record.Err = nil
+	expected := AuditRecord{
+		Path:     cmd.Path,
+		Args:     []string{"sh", "-c", "exit 4", "sh", "--password", "[REDACTED]"},
+		Dir:      dir,
+		EnvKeys:  []string{"A", "B"},
+		UID:      os.Getuid(),
+		GID:      os.Getgid(),
+		ExitCode: 4,
+	}
+	assert.Equal(t, expected, record)
+
+	record = records[1]
+	assert.Equal(t, []string{"/non/existent"}, record.Args)
+	assert.Equal(t, -1, record.ExitCode)
+	assert.Error(t, record.Err)
+}
@@ -0,0 +1,79 @@
+package command
+
+import (
+	"fmt"
+)
+
+// cappedBuffer is a writer keeping up to headMax bytes from the
+// start of the data written and up to tailMax bytes from the end.
+// If both headMax and tailMax are zero, all the data is kept.
+type cappedBuffer struct {
+	headMax int
+	tailMax int
+	head    []byte
+	tail    []byte
+	total   int64
+}
+
+func newCappedBuffer(headMax, tailMax int) *cappedBuffer {
+	return &cappedBuffer{
+		headMax: headMax,
+		tailMax: tailMax,
+	}
+}
+
+func (b *cappedBuffer) Write(p []byte) (n int, err error) {
+	n = len(p)
+	b.total += int64(n)
+
+	if b.headMax == 0 && b.tailMax == 0 {
+		b.head = append(b.head, p...)
+		return n, nil
+	}
+
+	if len(b.head) < b.headMax {
+		headLength := min(b.headMax-len(b.head), len(p))
+		b.head = append(b.head, p[:headLength]...)
+		p = p[headLength:]
+	}
+
+	if b.tailMax == 0 {
+		return n, nil
+	}
+
+	b.tail = append(b.tail, p...)
+	// Compact the tail only once it has grown to twice its
+	// maximum size, to amortize the copy cost.
+	if len(b.tail) > 2*b.tailMax { //nolint:gomnd
+		b.tail = append(b.tail[:0], b.tail[len(b.tail)-b.tailMax:]...)
+	}
+	return n, nil
+}
+
+// Truncated returns the number of bytes discarded.
+func (b *cappedBuffer) Truncated() (truncated int64) {
+	return b.total - int64(len(b.head)) - int64(len(b.keptTail()))
+}
+
+// Bytes returns the data kept, with a truncation marker
+// between the head and the tail if data was discarded.
+func (b *cappedBuffer) Bytes() []byte {
+	tail := b.keptTail()
+	var marker string
+	if truncated := b.Truncated(); truncated > 0 {
+		marker = fmt.Sprintf("\n[... %d bytes truncated ...]\n", truncated)
+	}
+
+	data := make([]byte, 0, len(b.head)+len(marker)+len(tail))
+	data = append(data, b.head...)
+	data = append(data, marker...)
+	data = append(data, tail...)
+	return data
+}
+
+func (b *cappedBuffer) keptTail() []byte {
+	if len(b.tail) <= b.tailMax {
+		return b.tail
+	}
+	return b.tail[len(b.tail)-b.tailMax:]
+}
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_cappedBuffer(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		headMax   int
+		tailMax   int
+		writes    []string
+		data      string
+		truncated int64
+	}{
+		"unlimited": {
+			writes: []string{"hello ", "world"},
+			data:   "hello world",
+		},
+		"under limits": {
+			headMax: 5,
+			tailMax: 10,
+			writes:  []string{"hello ", "world"},
+			data:    "hello world",
+		},
+		"head only": {
+			headMax:   5,
+			writes:    []string{"hel", "lo world"},
+			data:      "hello\n[... 6 bytes truncated ...]\n",
+			truncated: 6,
+		},
+		"tail only": {
+			tailMax:   5,
+			writes:    []string{"hello ", "wor", "ld"},
+			data:      "\n[... 6 bytes truncated ...]\nworld",
+			truncated: 6,
+		},
+		"head and tail": {
+			headMax:   2,
+			tailMax:   3,
+			writes:    []string{"abcdefghijklmnopqrstuvwxyz"},
+			data:      "ab\n[... 21 bytes truncated ...]\nxyz",
+			truncated: 21,
+		},
+		"tail compaction": {
+			tailMax:   2,
+			writes:    []string{"a", "b", "c", "d", "e", "f", "g"},
+			data:      "\n[... 5 bytes truncated ...]\nfg",
+			truncated: 5,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			buffer := newCappedBuffer(testCase.headMax, testCase.tailMax)
+			for _, write := range testCase.writes {
+				n, err := buffer.Write([]byte(write))
+				assert.NoError(t, err)
+				assert.Equal(t, len(write), n)
+			}
+
+			assert.Equal(t, testCase.data, string(buffer.Bytes()))
+			assert.Equal(t, testCase.truncated, buffer.Truncated())
+		})
+	}
+}
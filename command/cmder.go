@@ -1,8 +1,31 @@
 package command
 
 // Cmder handles running subprograms synchronously and asynchronously.
-type Cmder struct{}
+// Its methods modify the *exec.Cmd given according to the options:
+// RunAs and RunAsFileOwner set its SysProcAttr credential, EnvAllowlist
+// sets its Env, WorkingDir sets its Dir, and Timeout sets its WaitDelay
+// to one second if it is not set when running it with Run or RunWithOptions.
+type Cmder struct {
+	defaults []OptionSetter
+}
 
 func New() *Cmder {
 	return &Cmder{}
 }
+
+// NewWithDefaults creates a new Cmder using the option setters given
+// as defaults for every command it runs, including with Run and Start.
+// Options given to each method call are applied on top of these defaults.
+// Each method documents the options it ignores, if any.
+func NewWithDefaults(defaults ...OptionSetter) *Cmder {
+	return &Cmder{
+		defaults: defaults,
+	}
+}
+
+func (c *Cmder) newOptions(setters []OptionSetter) options {
+	allSetters := make([]OptionSetter, 0, len(c.defaults)+len(setters))
+	allSetters = append(allSetters, c.defaults...)
+	allSetters = append(allSetters, setters...)
+	return newOptions(allSetters)
+}
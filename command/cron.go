@@ -0,0 +1,239 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCronFieldsCount  = errors.New("cron expression fields count is not valid")
+	ErrCronMacroUnknown = errors.New("cron macro is unknown")
+	ErrCronValueInvalid = errors.New("cron value is not valid")
+	ErrCronValueRange   = errors.New("cron value is out of range")
+	ErrCronStepInvalid  = errors.New("cron step is not valid")
+)
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	seconds, minutes, hours, daysOfMonth, months, daysOfWeek uint64
+	// anyDayOfMonth and anyDayOfWeek are true if the corresponding
+	// field is a wildcard, to apply the standard cron rule where
+	// a day matches either field if both fields are restricted.
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+//nolint:gochecknoglobals,gomnd
+var (
+	cronSeconds     = cronField{name: "seconds", min: 0, max: 59}
+	cronMinutes     = cronField{name: "minutes", min: 0, max: 59}
+	cronHours       = cronField{name: "hours", min: 0, max: 23}
+	cronDaysOfMonth = cronField{name: "day of month", min: 1, max: 31}
+	cronMonths      = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// cronDaysOfWeek accepts 7 as Sunday, which is mapped to 0.
+	cronDaysOfWeek = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+	cronMacros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// ParseSchedule parses a standard cron expression with either 5 fields
+// (minutes, hours, day of month, month and day of week) or 6 fields
+// with a leading seconds field. Each field can be a wildcard `*`,
+// a value, a range `a-b`, a step `*/n`, `a/n` or `a-b/n`, or a comma
+// separated list of these. Months and days of week can be given by
+// their three letters English names, such as `jan` or `mon`, and
+// the day of month and day of week fields also accept `?` as a
+// wildcard. The macros @yearly, @annually, @monthly, @weekly, @daily,
+// @midnight and @hourly are supported as well.
+func ParseSchedule(expression string) (schedule *Schedule, err error) {
+	expression = strings.TrimSpace(expression)
+	if strings.HasPrefix(expression, "@") {
+		macroExpression, ok := cronMacros[strings.ToLower(expression)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrCronMacroUnknown, expression)
+		}
+		expression = macroExpression
+	}
+
+	fields := strings.Fields(expression)
+	const fieldsWithoutSeconds, fieldsWithSeconds = 5, 6
+	switch len(fields) {
+	case fieldsWithoutSeconds:
+		fields = append([]string{"0"}, fields...)
+	case fieldsWithSeconds:
+	default:
+		return nil, fmt.Errorf("%w: expected %d or %d fields but got %d",
+			ErrCronFieldsCount, fieldsWithoutSeconds, fieldsWithSeconds, len(fields))
+	}
+
+	schedule = &Schedule{
+		anyDayOfMonth: isCronWildcard(fields[3]),
+		anyDayOfWeek:  isCronWildcard(fields[5]),
+	}
+	bitsets := []*uint64{
+		&schedule.seconds, &schedule.minutes, &schedule.hours,
+		&schedule.daysOfMonth, &schedule.months, &schedule.daysOfWeek,
+	}
+	cronFields := []cronField{
+		cronSeconds, cronMinutes, cronHours,
+		cronDaysOfMonth, cronMonths, cronDaysOfWeek,
+	}
+	for i, field := range fields {
+		*bitsets[i], err = parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s field: %w", cronFields[i].name, err)
+		}
+	}
+
+	const sundayAsSeven, sundayAsZero = 1 << 7, 1 << 0
+	if schedule.daysOfWeek&sundayAsSeven != 0 {
+		schedule.daysOfWeek = schedule.daysOfWeek&^sundayAsSeven | sundayAsZero
+	}
+
+	return schedule, nil
+}
+
+func isCronWildcard(field string) bool {
+	return field == "*" || field == "?"
+}
+
+func parseCronField(field string, cronField cronField) (bitset uint64, err error) {
+	for _, item := range strings.Split(field, ",") {
+		itemBitset, err := parseCronItem(item, cronField)
+		if err != nil {
+			return 0, err
+		}
+		bitset |= itemBitset
+	}
+	return bitset, nil
+}
+
+func parseCronItem(item string, cronField cronField) (bitset uint64, err error) {
+	rangePart, stepPart, hasStep := strings.Cut(item, "/")
+	step := 1
+	if hasStep {
+		step, err = strconv.Atoi(stepPart)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("%w: %s", ErrCronStepInvalid, stepPart)
+		}
+	}
+
+	var start, end int
+	switch {
+	case isCronWildcard(rangePart):
+		start, end = cronField.min, cronField.max
+	case strings.Contains(rangePart, "-"):
+		startPart, endPart, _ := strings.Cut(rangePart, "-")
+		start, err = parseCronValue(startPart, cronField)
+		if err != nil {
+			return 0, err
+		}
+		end, err = parseCronValue(endPart, cronField)
+		if err != nil {
+			return 0, err
+		}
+		if start > end {
+			return 0, fmt.Errorf("%w: range start %d is after range end %d",
+				ErrCronValueRange, start, end)
+		}
+	default:
+		start, err = parseCronValue(rangePart, cronField)
+		if err != nil {
+			return 0, err
+		}
+		end = start
+		if hasStep {
+			// a/n means from a to the maximum every n
+			end = cronField.max
+		}
+	}
+
+	for value := start; value <= end; value += step {
+		bitset |= 1 << value
+	}
+	return bitset, nil
+}
+
+func parseCronValue(s string, cronField cronField) (value int, err error) {
+	value, ok := cronField.names[strings.ToLower(s)]
+	if !ok {
+		value, err = strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s", ErrCronValueInvalid, s)
+		}
+	}
+
+	if value < cronField.min || value > cronField.max {
+		return 0, fmt.Errorf("%w: %d must be between %d and %d",
+			ErrCronValueRange, value, cronField.min, cronField.max)
+	}
+	return value, nil
+}
+
+// Next returns the first time matching the schedule strictly after
+// the time given, in the location of the time given. It returns the
+// zero time if no time matches within the next five years, for
+// example for the schedule `0 0 30 2 *`.
+func (s *Schedule) Next(t time.Time) time.Time {
+	location := t.Location()
+	t = t.Truncate(time.Second).Add(time.Second)
+	const maxYears = 5
+	yearLimit := t.Year() + maxYears
+
+	for t.Year() <= yearLimit {
+		year, month, day := t.Date()
+		hour, minute, second := t.Clock()
+		switch {
+		case !hasBit(s.months, int(month)):
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, location)
+		case !s.matchDay(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, location)
+		case !hasBit(s.hours, hour):
+			t = time.Date(year, month, day, hour+1, 0, 0, 0, location)
+		case !hasBit(s.minutes, minute):
+			t = time.Date(year, month, day, hour, minute+1, 0, 0, location)
+		case !hasBit(s.seconds, second):
+			t = t.Add(time.Second)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	dayOfMonthMatch := hasBit(s.daysOfMonth, t.Day())
+	dayOfWeekMatch := hasBit(s.daysOfWeek, int(t.Weekday()))
+	switch {
+	case s.anyDayOfMonth:
+		return dayOfWeekMatch
+	case s.anyDayOfWeek:
+		return dayOfMonthMatch
+	default:
+		return dayOfMonthMatch || dayOfWeekMatch
+	}
+}
+
+func hasBit(bitset uint64, bit int) bool {
+	return bitset&(1<<bit) != 0
+}
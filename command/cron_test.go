@@ -0,0 +1,146 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseSchedule(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		expression string
+		errWrapped error
+		errMessage string
+	}{
+		"five fields": {
+			expression: "*/5 1-3 * jan,MAR mon-fri",
+		},
+		"six fields": {
+			expression: "30 0 12 ? * 7",
+		},
+		"macro": {
+			expression: "@Daily",
+		},
+		"unknown macro": {
+			expression: "@reboot",
+			errWrapped: ErrCronMacroUnknown,
+			errMessage: "cron macro is unknown: @reboot",
+		},
+		"fields count": {
+			expression: "* * * *",
+			errWrapped: ErrCronFieldsCount,
+			errMessage: "cron expression fields count is not valid: expected 5 or 6 fields but got 4",
+		},
+		"invalid value": {
+			expression: "* x * * *",
+			errWrapped: ErrCronValueInvalid,
+			errMessage: "parsing hours field: cron value is not valid: x",
+		},
+		"out of range": {
+			expression: "60 * * * *",
+			errWrapped: ErrCronValueRange,
+			errMessage: "parsing minutes field: cron value is out of range: 60 must be between 0 and 59",
+		},
+		"reversed range": {
+			expression: "* * 5-2 * *",
+			errWrapped: ErrCronValueRange,
+			errMessage: "parsing day of month field: cron value is out of range: range start 5 is after range end 2",
+		},
+		"invalid step": {
+			expression: "*/0 * * * *",
+			errWrapped: ErrCronStepInvalid,
+			errMessage: "parsing minutes field: cron step is not valid: 0",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			schedule, err := ParseSchedule(testCase.expression)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+				assert.Nil(t, schedule)
+			} else {
+				assert.NotNil(t, schedule)
+			}
+		})
+	}
+}
+
+func Test_Schedule_Next(t *testing.T) {
+	t.Parallel()
+
+	// Saturday 7 January 2023
+	from := time.Date(2023, time.January, 7, 10, 7, 30, 0, time.UTC)
+
+	testCases := map[string]struct {
+		expression string
+		next       time.Time
+	}{
+		"every 15 minutes": {
+			expression: "*/15 * * * *",
+			next:       time.Date(2023, time.January, 7, 10, 15, 0, 0, time.UTC),
+		},
+		"every second": {
+			expression: "* * * * * *",
+			next:       time.Date(2023, time.January, 7, 10, 7, 31, 0, time.UTC),
+		},
+		"seconds step from value": {
+			expression: "40/10 * * * * *",
+			next:       time.Date(2023, time.January, 7, 10, 7, 40, 0, time.UTC),
+		},
+		"hourly": {
+			expression: "@hourly",
+			next:       time.Date(2023, time.January, 7, 11, 0, 0, 0, time.UTC),
+		},
+		"week days": {
+			expression: "30 9 * * mon-fri",
+			next:       time.Date(2023, time.January, 9, 9, 30, 0, 0, time.UTC),
+		},
+		"sunday as 7": {
+			expression: "0 0 * * 7",
+			next:       time.Date(2023, time.January, 8, 0, 0, 0, 0, time.UTC),
+		},
+		"day of month or day of week": {
+			expression: "0 0 10 * tue",
+			next:       time.Date(2023, time.January, 10, 0, 0, 0, 0, time.UTC),
+		},
+		"day of month or day of week first": {
+			expression: "0 0 9 * tue",
+			next:       time.Date(2023, time.January, 9, 0, 0, 0, 0, time.UTC),
+		},
+		"month name": {
+			expression: "0 12 1 MAR *",
+			next:       time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC),
+		},
+		"leap day": {
+			expression: "0 0 29 feb *",
+			next:       time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		"never": {
+			expression: "0 0 30 2 *",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			schedule, err := ParseSchedule(testCase.expression)
+			require.NoError(t, err)
+
+			next := schedule.Next(from)
+
+			assert.Equal(t, testCase.next, next)
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeCommand is a scripted command execution for the Fake cmder.
+type FakeCommand struct {
+	// Path is a regular expression matching the full command path.
+	Path string `json:"path"`
+	// Args are regular expressions each matching the full argument
+	// at the same position, excluding the command name. The number
+	// of arguments must match the number of patterns.
+	Args []string `json:"args"`
+	// Stdout are the lines written to stdout.
+	Stdout []string `json:"stdout,omitempty"`
+	// Stderr are the lines written to stderr.
+	Stderr []string `json:"stderr,omitempty"`
+	// Delay is the duration to wait for after the output is written
+	// and before the command exits. It is in nanoseconds in JSON.
+	Delay time.Duration `json:"delay,omitempty"`
+	// ExitCode is the exit code of the command.
+	ExitCode int `json:"exit_code"`
+	// Times is the number of times the command can be matched,
+	// and zero means it can be matched an unlimited number of times.
+	Times int `json:"times,omitempty"`
+}
+
+// FakeExitError is returned by the Fake cmder for a scripted
+// command exiting with a non-zero exit code.
+type FakeExitError struct {
+	Code int
+}
+
+func (e *FakeExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the exit code of the fake command.
+func (e *FakeExitError) ExitCode() int {
+	return e.Code
+}
+
+var (
+	ErrFakeNoMatch = errors.New("no fake command matching")
+	ErrFakeKilled  = errors.New("signal: killed")
+)
+
+// Fake is a fake cmder replaying scripted command executions, matching
+// each command against the scripted commands in their order.
+// It is meant to be used in tests instead of a *Cmder.
+type Fake struct {
+	commands []fakeMatcher
+	rlimits  []map[string]uint64
+	mutex    sync.Mutex
+}
+
+type fakeMatcher struct {
+	command FakeCommand
+	path    *regexp.Regexp
+	args    []*regexp.Regexp
+	matched int
+}
+
+// NewFake creates a fake cmder replaying the commands given.
+// It returns an error if a path or argument pattern is invalid.
+func NewFake(commands []FakeCommand) (fake *Fake, err error) {
+	fake = &Fake{
+		commands: make([]fakeMatcher, len(commands)),
+	}
+	for i, command := range commands {
+		matcher := fakeMatcher{
+			command: command,
+			args:    make([]*regexp.Regexp, len(command.Args)),
+		}
+		matcher.path, err = compileFullRegex(command.Path)
+		if err != nil {
+			return nil, fmt.Errorf("command %d path: %w", i, err)
+		}
+		for j, arg := range command.Args {
+			matcher.args[j], err = compileFullRegex(arg)
+			if err != nil {
+				return nil, fmt.Errorf("command %d argument %d: %w", i, j, err)
+			}
+		}
+		fake.commands[i] = matcher
+	}
+	return fake, nil
+}
+
+// LoadFake creates a fake cmder replaying the commands
+// from the JSON fixture file at the path given.
+func LoadFake(path string) (fake *Fake, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading fixture file: %w", err)
+	}
+
+	var commands []FakeCommand
+	err = json.Unmarshal(data, &commands)
+	if err != nil {
+		return nil, fmt.Errorf("decoding fixture file: %w", err)
+	}
+
+	return NewFake(commands)
+}
+
+func compileFullRegex(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+func (f *Fake) match(cmd *exec.Cmd) (command FakeCommand, err error) {
+	var args []string
+	if len(cmd.Args) > 1 {
+		args = cmd.Args[1:]
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for i := range f.commands {
+		matcher := &f.commands[i]
+		if matcher.command.Times > 0 && matcher.matched >= matcher.command.Times {
+			continue
+		}
+		if !matcher.matches(cmd.Path, args) {
+			continue
+		}
+		matcher.matched++
+		return matcher.command, nil
+	}
+
+	return FakeCommand{}, fmt.Errorf("%w: %s", ErrFakeNoMatch, cmd.String())
+}
+
+func (m *fakeMatcher) matches(path string, args []string) bool {
+	if !m.path.MatchString(path) || len(args) != len(m.args) {
+		return false
+	}
+	for i, arg := range args {
+		if !m.args[i].MatchString(arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// Run replays the command matching the command given, returning
+// its combined stdout and stderr lines as output.
+func (f *Fake) Run(cmd *exec.Cmd) (output string, err error) {
+	command, err := f.match(cmd)
+	if err != nil {
+		return "", err
+	}
+	return run(newFakeCmd(command))
+}
+
+// RunWithOptions replays the command matching the command given,
+// applying the options given like Cmder.RunWithOptions.
+func (f *Fake) RunWithOptions(cmd *exec.Cmd, setters ...OptionSetter) (
+	result Result, err error) {
+	command, err := f.match(cmd)
+	if err != nil {
+		return Result{ExitCode: -1}, err
+	}
+	return runWithOptions(newFakeCmd(command), f.newOptions(setters))
+}
+
+// Start replays the command matching the command given,
+// streaming its stdout and stderr lines like Cmder.Start.
+func (f *Fake) Start(cmd *exec.Cmd) (stdoutLines, stderrLines <-chan string,
+	waitError <-chan error, startErr error) {
+	command, err := f.match(cmd)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return start(newFakeCmd(command))
+}
+
+// StartProcess replays the command matching the command given,
+// returning a process handle like Cmder.StartProcess.
+func (f *Fake) StartProcess(cmd *exec.Cmd, setters ...OptionSetter) (
+	process *Process, err error) {
+	command, err := f.match(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return startProcess(newFakeCmd(command), f.newOptions(setters))
+}
+
+// newOptions returns the options from the setters given, recording
+// and removing the resource limits since a fake command has no
+// process to apply them to.
+func (f *Fake) newOptions(setters []OptionSetter) (options options) {
+	options = newOptions(setters)
+	var rlimits map[string]uint64
+	if len(options.rlimits) > 0 {
+		rlimits = make(map[string]uint64, len(options.rlimits))
+		for _, limit := range options.rlimits {
+			rlimits[limit.resource.String()] = limit.value
+		}
+	}
+	options.rlimits = nil
+
+	f.mutex.Lock()
+	f.rlimits = append(f.rlimits, rlimits)
+	f.mutex.Unlock()
+	return options
+}
+
+// RLimits returns the resource limits requested for each command
+// replayed with RunWithOptions or StartProcess, in the order the
+// commands were replayed. Each map is keyed by resource name, such
+// as "open files", and is nil if no resource limit was requested.
+// Resource limits are only recorded and never applied.
+func (f *Fake) RLimits() (rlimits []map[string]uint64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	rlimits = make([]map[string]uint64, len(f.rlimits))
+	copy(rlimits, f.rlimits)
+	return rlimits
+}
+
+// fakeCmd implements the processCmd interface for a fake command.
+type fakeCmd struct {
+	command      FakeCommand
+	output       io.Writer
+	stdoutWriter *io.PipeWriter
+	stderrWriter *io.PipeWriter
+	written      sync.WaitGroup
+	killed       chan struct{}
+	killOnce     sync.Once
+}
+
+func newFakeCmd(command FakeCommand) *fakeCmd {
+	return &fakeCmd{
+		command: command,
+		killed:  make(chan struct{}),
+	}
+}
+
+func (c *fakeCmd) CombinedOutput() ([]byte, error) {
+	lines := make([]string, 0, len(c.command.Stdout)+len(c.command.Stderr))
+	lines = append(lines, c.command.Stdout...)
+	lines = append(lines, c.command.Stderr...)
+	var output []byte
+	if len(lines) > 0 {
+		output = []byte(strings.Join(lines, "\n") + "\n")
+	}
+	return output, c.wait()
+}
+
+func (c *fakeCmd) StdoutPipe() (io.ReadCloser, error) {
+	reader, writer := io.Pipe()
+	c.stdoutWriter = writer
+	return reader, nil
+}
+
+func (c *fakeCmd) StderrPipe() (io.ReadCloser, error) {
+	reader, writer := io.Pipe()
+	c.stderrWriter = writer
+	return reader, nil
+}
+
+func (c *fakeCmd) SetOutput(writer io.Writer) {
+	c.output = writer
+}
+
+func (c *fakeCmd) Start() error {
+	if c.output != nil {
+		writeLines(c.output, c.command.Stdout)
+		writeLines(c.output, c.command.Stderr)
+	}
+
+	streams := []struct {
+		writer *io.PipeWriter
+		lines  []string
+	}{
+		{writer: c.stdoutWriter, lines: c.command.Stdout},
+		{writer: c.stderrWriter, lines: c.command.Stderr},
+	}
+	for _, stream := range streams {
+		if stream.writer == nil {
+			continue
+		}
+		c.written.Add(1)
+		go func(writer *io.PipeWriter, lines []string) {
+			defer c.written.Done()
+			writeLines(writer, lines)
+			_ = writer.Close()
+		}(stream.writer, stream.lines)
+	}
+	return nil
+}
+
+func writeLines(writer io.Writer, lines []string) {
+	for _, line := range lines {
+		_, err := io.WriteString(writer, line+"\n")
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *fakeCmd) Wait() error {
+	c.written.Wait()
+	return c.wait()
+}
+
+func (c *fakeCmd) wait() error {
+	timer := time.NewTimer(c.command.Delay)
+	select {
+	case <-timer.C:
+	case <-c.killed:
+		timer.Stop()
+		return ErrFakeKilled
+	}
+
+	if c.command.ExitCode != 0 {
+		return &FakeExitError{Code: c.command.ExitCode}
+	}
+	return nil
+}
+
+func (c *fakeCmd) PID() int {
+	return 0
+}
+
+func (c *fakeCmd) Signal(signal os.Signal) error {
+	if signal == os.Kill {
+		c.killOnce.Do(func() { close(c.killed) })
+	}
+	return nil
+}
+
+func (c *fakeCmd) State() *os.ProcessState {
+	return nil
+}
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewFake(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewFake([]FakeCommand{{Path: "("}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command 0 path: ")
+
+	_, err = NewFake([]FakeCommand{{Path: "sh", Args: []string{"a", "["}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command 0 argument 1: ")
+}
+
+func Test_Fake(t *testing.T) {
+	t.Parallel()
+
+	commands := []FakeCommand{
+		{
+			Path:   "/usr/bin/tool",
+			Args:   []string{"--once"},
+			Stdout: []string{"first"},
+			Times:  1,
+		},
+		{
+			Path:     ".*/tool",
+			Args:     []string{"--.+"},
+			Stdout:   []string{"hello", "world"},
+			Stderr:   []string{"warning"},
+			ExitCode: 2,
+		},
+		{
+			Path:  "/usr/bin/sleeper",
+			Delay: time.Hour,
+		},
+	}
+
+	t.Run("run", func(t *testing.T) {
+		t.Parallel()
+
+		fake, err := NewFake(commands)
+		require.NoError(t, err)
+
+		output, err := fake.Run(exec.Command("/usr/bin/tool", "--once"))
+		require.NoError(t, err)
+		assert.Equal(t, "first", output)
+
+		output, err = fake.Run(exec.Command("/usr/bin/tool", "--once"))
+		var exitErr *FakeExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 2, exitErr.ExitCode())
+		assert.Equal(t, "hello\nworld\nwarning", output)
+
+		_, err = fake.Run(exec.Command("/usr/bin/tool"))
+		assert.ErrorIs(t, err, ErrFakeNoMatch)
+	})
+
+	t.Run("run with options", func(t *testing.T) {
+		t.Parallel()
+
+		fake, err := NewFake(commands)
+		require.NoError(t, err)
+
+		result, err := fake.RunWithOptions(exec.Command("/opt/tool", "--x"),
+			MaxOutput(5, 0))
+		require.Error(t, err)
+		assert.Equal(t, 2, result.ExitCode)
+		assert.Equal(t, "hello\n[... 15 bytes truncated ...]", result.Output)
+
+		result, err = fake.RunWithOptions(exec.Command("/usr/bin/sleeper"),
+			Timeout(time.Millisecond))
+		assert.ErrorIs(t, err, ErrTimedOut)
+		assert.ErrorIs(t, err, ErrFakeKilled)
+		assert.True(t, result.TimedOut)
+		assert.Equal(t, -1, result.ExitCode)
+	})
+
+	t.Run("start process", func(t *testing.T) {
+		t.Parallel()
+
+		fake, err := NewFake(commands)
+		require.NoError(t, err)
+
+		process, err := fake.StartProcess(exec.Command("/opt/tool", "--x"))
+		require.NoError(t, err)
+
+		stdoutLines, stderrLines := readAllLines(t, process.Stdout(), process.Stderr())
+
+		err = process.Wait()
+		require.Error(t, err)
+		assert.Equal(t, "exit status 2", err.Error())
+		assert.Equal(t, 2, process.ExitCode())
+		assert.Equal(t, []string{"hello", "world"}, stdoutLines)
+		assert.Equal(t, []string{"warning"}, stderrLines)
+	})
+}
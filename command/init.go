@@ -0,0 +1,105 @@
+package command
+
+import (
+	"os"
+	"sync"
+	"syscall"
+)
+
+// InitSettings are the settings for an Init.
+type InitSettings struct {
+	// Subreaper sets the current process as a child subreaper, such
+	// that orphaned descendant processes are reparented to it and can
+	// be reaped, even if it is not running as PID 1.
+	// It defaults to false.
+	Subreaper bool
+	// ForwardSignals are the signals received by the current process
+	// and forwarded to the supervised processes.
+	// It defaults to SIGTERM and SIGINT, and can be set to an empty
+	// non-nil slice to disable signal forwarding.
+	ForwardSignals []os.Signal
+}
+
+func (s *InitSettings) setDefaults() {
+	if s.ForwardSignals == nil {
+		s.ForwardSignals = []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+	}
+}
+
+// Init is a helper for a program running as PID 1, typically in a
+// container. It reaps zombie processes, and forwards signals received
+// to the processes started by a Cmder using the SupervisedBy option.
+// It never reaps the processes it supervises, such that their exit
+// status can still be obtained by waiting for them.
+type Init struct {
+	settings InitSettings
+
+	// reapMutex is held for reading while starting a supervised process
+	// and registering its PID, and held for writing while reaping, so a
+	// supervised process cannot be reaped before it is registered.
+	reapMutex sync.RWMutex
+	pidsMutex sync.Mutex
+	pids      map[int]struct{}
+}
+
+// NewInit creates a new Init with the settings given.
+// Its Run method must be called for it to reap zombie processes
+// and forward signals.
+func NewInit(settings InitSettings) *Init {
+	settings.setDefaults()
+	return &Init{
+		settings: settings,
+		pids:     make(map[int]struct{}),
+	}
+}
+
+// SupervisedBy registers the processes started with the init given,
+// such that the init forwards signals to them and does not reap them.
+// All the child processes of the current process must be started with
+// this option, or their exit status may be reaped by the init.
+func SupervisedBy(initProcess *Init) OptionSetter {
+	return func(o *options) {
+		o.init = initProcess
+	}
+}
+
+func (i *Init) start(cmd cmdAdapter) error {
+	i.reapMutex.RLock()
+	defer i.reapMutex.RUnlock()
+
+	err := cmd.start()
+	if err != nil {
+		return err
+	}
+
+	i.pidsMutex.Lock()
+	i.pids[cmd.PID()] = struct{}{}
+	i.pidsMutex.Unlock()
+	return nil
+}
+
+func (i *Init) unregister(pid int) {
+	i.pidsMutex.Lock()
+	delete(i.pids, pid)
+	i.pidsMutex.Unlock()
+}
+
+func (i *Init) isSupervised(pid int) (supervised bool) {
+	i.pidsMutex.Lock()
+	_, supervised = i.pids[pid]
+	i.pidsMutex.Unlock()
+	return supervised
+}
+
+// forward sends the signal given to all the supervised processes.
+func (i *Init) forward(signal os.Signal) {
+	i.pidsMutex.Lock()
+	defer i.pidsMutex.Unlock()
+	for pid := range i.pids {
+		process, err := os.FindProcess(pid)
+		if err != nil {
+			continue
+		}
+		_ = process.Signal(signal)
+	}
+}
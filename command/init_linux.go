@@ -0,0 +1,125 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Run reaps zombie child processes and forwards signals to the
+// supervised processes until the context is canceled.
+// It returns an error if the current process cannot be set
+// as a child subreaper.
+func (i *Init) Run(ctx context.Context) error {
+	if i.settings.Subreaper {
+		const enabled = 1
+		err := unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, enabled, 0, 0, 0)
+		if err != nil {
+			return fmt.Errorf("setting child subreaper: %w", err)
+		}
+	}
+
+	childSignals := make(chan os.Signal, 1)
+	signal.Notify(childSignals, syscall.SIGCHLD)
+	defer signal.Stop(childSignals)
+
+	forwardSignals := make(chan os.Signal, len(i.settings.ForwardSignals))
+	if len(i.settings.ForwardSignals) > 0 {
+		signal.Notify(forwardSignals, i.settings.ForwardSignals...)
+		defer signal.Stop(forwardSignals)
+	}
+
+	// Reap zombies which exited before the signal notification.
+	i.reap()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-childSignals:
+			i.reap()
+		case signal := <-forwardSignals:
+			i.forward(signal)
+		}
+	}
+}
+
+// reap reaps all the zombie child processes not supervised.
+// Since SIGCHLD signals can coalesce, it reaps all the zombies
+// found and not only one.
+func (i *Init) reap() {
+	i.reapMutex.Lock()
+	defer i.reapMutex.Unlock()
+
+	pids, err := zombieChildren(os.Getpid())
+	if err != nil {
+		return
+	}
+
+	for _, pid := range pids {
+		if i.isSupervised(pid) {
+			continue
+		}
+		var status unix.WaitStatus
+		_, _ = unix.Wait4(pid, &status, unix.WNOHANG, nil)
+	}
+}
+
+// zombieChildren returns the process IDs of the zombie
+// child processes of the parent process ID given.
+func zombieChildren(parentPID int) (pids []int, err error) {
+	statPaths, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		return nil, fmt.Errorf("listing processes: %w", err)
+	}
+
+	for _, statPath := range statPaths {
+		data, err := os.ReadFile(statPath)
+		if err != nil {
+			// process exited and was reaped in the meantime
+			continue
+		}
+		pid, ppid, state, ok := parseProcStat(data)
+		if ok && ppid == parentPID && state == 'Z' {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// parseProcStat parses the process ID, parent process ID and state
+// from the content of a /proc/<pid>/stat file, which has the format
+// "pid (comm) state ppid ...", where comm can contain spaces and
+// parentheses.
+func parseProcStat(data []byte) (pid, ppid int, state byte, ok bool) {
+	commStart := bytes.IndexByte(data, '(')
+	commEnd := bytes.LastIndexByte(data, ')')
+	if commStart == -1 || commEnd == -1 || commEnd < commStart {
+		return 0, 0, 0, false
+	}
+
+	pid, err := strconv.Atoi(string(bytes.TrimSpace(data[:commStart])))
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	const minFields = 2
+	fields := bytes.Fields(data[commEnd+1:])
+	if len(fields) < minFields || len(fields[0]) != 1 {
+		return 0, 0, 0, false
+	}
+	state = fields[0][0]
+	ppid, err = strconv.Atoi(string(fields[1]))
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	return pid, ppid, state, true
+}
@@ -0,0 +1,125 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseProcStat(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		data  string
+		pid   int
+		ppid  int
+		state byte
+		ok    bool
+	}{
+		"empty": {},
+		"malformed pid": {
+			data: "x (sh) S 1 2 3",
+		},
+		"missing fields": {
+			data: "12 (sh) S",
+		},
+		"valid": {
+			data:  "12 (sh) Z 1 12 12 0 -1",
+			pid:   12,
+			ppid:  1,
+			state: 'Z',
+			ok:    true,
+		},
+		"command with spaces and parentheses": {
+			data:  "12 (a (b) c) S 34 12 12 0 -1",
+			pid:   12,
+			ppid:  34,
+			state: 'S',
+			ok:    true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pid, ppid, state, ok := parseProcStat([]byte(testCase.data))
+
+			assert.Equal(t, testCase.pid, pid)
+			assert.Equal(t, testCase.ppid, ppid)
+			assert.Equal(t, testCase.state, state)
+			assert.Equal(t, testCase.ok, ok)
+		})
+	}
+}
+
+// Test_Init is not parallel since the init reaps all the child processes
+// of the test process which are not supervised by it.
+func Test_Init(t *testing.T) {
+	// Ensure the test process is not terminated by the signal
+	// forwarded, even if the init is not yet listening for it.
+	testSignals := make(chan os.Signal, 1)
+	signal.Notify(testSignals, syscall.SIGUSR1)
+	defer signal.Stop(testSignals)
+
+	initProcess := NewInit(InitSettings{
+		Subreaper:      true,
+		ForwardSignals: []os.Signal{syscall.SIGUSR1},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error)
+	go func() { runErr <- initProcess.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-runErr)
+	}()
+
+	cmder := NewWithDefaults(SupervisedBy(initProcess))
+
+	// The orphaned sleep process is reparented to the test process.
+	output, err := cmder.Run(exec.Command("sh", "-c", "sleep 0.1 & echo $!"))
+	require.NoError(t, err)
+	orphanPID, err := strconv.Atoi(output)
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		result, err := cmder.RunWithOptions(exec.Command("sh", "-c", "exit 3"))
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		require.Equal(t, 3, result.ExitCode)
+	}
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(fmt.Sprintf("/proc/%d", orphanPID))
+		return errors.Is(err, os.ErrNotExist)
+	}, 5*time.Second, 10*time.Millisecond, "orphan zombie process was not reaped")
+
+	cmd := exec.Command("sh", "-c",
+		`trap 'echo signaled; exit 0' USR1; echo ready; while :; do sleep 0.01; done`)
+	process, err := cmder.StartProcess(cmd)
+	require.NoError(t, err)
+	line := <-process.Stdout()
+	require.Equal(t, "ready", line)
+
+	err = syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	require.NoError(t, err)
+
+	line = <-process.Stdout()
+	assert.Equal(t, "signaled", line)
+	_, ok := <-process.Stdout()
+	assert.False(t, ok)
+	_, ok = <-process.Stderr()
+	assert.False(t, ok)
+	assert.NoError(t, process.Wait())
+}
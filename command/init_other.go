@@ -0,0 +1,16 @@
+//go:build !linux
+
+package command
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrInitNotSupported = errors.New("init is only supported on Linux")
+
+// Run is only supported on Linux and returns an error
+// on other platforms.
+func (i *Init) Run(context.Context) error {
+	return ErrInitNotSupported
+}
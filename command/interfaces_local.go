@@ -1,6 +1,9 @@
 package command
 
-import "io"
+import (
+	"io"
+	"os"
+)
 
 type execCmd interface {
 	CombinedOutput() ([]byte, error)
@@ -9,3 +12,11 @@ type execCmd interface {
 	Start() error
 	Wait() error
 }
+
+type processCmd interface {
+	execCmd
+	PID() int
+	Signal(signal os.Signal) error
+	State() *os.ProcessState
+	SetOutput(writer io.Writer)
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// JSONLineError is sent for each line which cannot be decoded
+// as JSON by DecodeJSONLines.
+type JSONLineError struct {
+	// Number is the line number in the output, starting from 1,
+	// where a line split into chunks counts as a single line.
+	Number int
+	// Line is the line which cannot be decoded.
+	Line string
+	// Err is the JSON decoding error.
+	Err error
+}
+
+func (e *JSONLineError) Error() string {
+	return fmt.Sprintf("decoding JSON line %d: %s", e.Number, e.Err)
+}
+
+func (e *JSONLineError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeJSONLines decodes each line received from the lines channel as
+// a JSON value of type T, and sends each decoded value on the values
+// channel. Lines which cannot be decoded are sent on the errs channel as
+// *JSONLineError errors, and blank lines are ignored. Both channels must
+// be read until they are closed, which happens once the lines channel
+// is closed. It is typically used with the Stdout channel of a Process.
+// Lines longer than the maximum line size, which are streamed as chunks,
+// are reassembled before being decoded. For this, the MaxLineSize option
+// given must be the same as the one used to start the process, and the
+// other options are ignored. A chunk is joined with the next one if it
+// has exactly the maximum line size and the line so far is an incomplete
+// JSON value.
+func DecodeJSONLines[T any](lines <-chan string, setters ...OptionSetter) (
+	values <-chan T, errs <-chan error) {
+	maxLineSize := newOptions(setters).maxLineSize
+	valuesCh := make(chan T)
+	errsCh := make(chan error)
+
+	go func() {
+		defer close(valuesCh)
+		defer close(errsCh)
+
+		lineNumber := 0
+		var data []byte
+		decode := func() {
+			lineNumber++
+			line := string(data)
+			data = nil
+			if strings.TrimSpace(line) == "" {
+				return
+			}
+
+			var value T
+			err := json.Unmarshal([]byte(line), &value)
+			if err != nil {
+				errsCh <- &JSONLineError{Number: lineNumber, Line: line, Err: err}
+				return
+			}
+			valuesCh <- value
+		}
+
+		for chunk := range lines {
+			data = append(data, chunk...)
+			if len(chunk) == maxLineSize && isJSONIncomplete(data) {
+				// the line continues in the next chunk
+				continue
+			}
+			decode()
+		}
+
+		if len(data) > 0 {
+			// the output ended with a chunk of the maximum line size
+			decode()
+		}
+	}()
+
+	return valuesCh, errsCh
+}
+
+// isJSONIncomplete returns true if the data is not valid JSON
+// only because it ends before the end of the JSON value.
+func isJSONIncomplete(data []byte) bool {
+	var value json.RawMessage
+	err := json.Unmarshal(data, &value)
+	var syntaxErr *json.SyntaxError
+	return errors.As(err, &syntaxErr) && syntaxErr.Offset == int64(len(data))
+}
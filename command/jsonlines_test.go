@@ -0,0 +1,164 @@
+package command
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DecodeJSONLines(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		Name  string `json:"name"`
+		Value int    `json:"value"`
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		lines <- `{"name":"a","value":1}`
+		lines <- ""
+		lines <- `not json`
+		lines <- `{"name":"b","value":2}`
+	}()
+
+	values, errs := DecodeJSONLines[event](lines)
+
+	var decoded []event
+	var decodeErrs []error
+	for values != nil || errs != nil {
+		select {
+		case value, ok := <-values:
+			if !ok {
+				values = nil
+				continue
+			}
+			decoded = append(decoded, value)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			decodeErrs = append(decodeErrs, err)
+		}
+	}
+
+	assert.Equal(t, []event{{Name: "a", Value: 1}, {Name: "b", Value: 2}}, decoded)
+	require.Len(t, decodeErrs, 1)
+	var lineErr *JSONLineError
+	require.ErrorAs(t, decodeErrs[0], &lineErr)
+	assert.Equal(t, 3, lineErr.Number)
+	assert.Equal(t, "not json", lineErr.Line)
+	assert.Equal(t, "decoding JSON line 3: invalid character 'o' in literal null (expecting 'u')",
+		lineErr.Error())
+}
+
+func Test_DecodeJSONLines_chunks(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		Name  string `json:"name"`
+		Value int    `json:"value"`
+	}
+
+	const maxLineSize = 8
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		// {"name":"a","value":1} split into chunks
+		lines <- `{"name":`
+		lines <- `"a","val`
+		lines <- `ue":1}`
+		// invalid line of the maximum line size
+		lines <- `not json`
+		// incomplete line shorter than the maximum line size
+		lines <- `{"name"`
+		lines <- `{"value":2}`
+		// last line ending with a chunk of the maximum line size
+		lines <- `{"name":`
+	}()
+
+	values, errs := DecodeJSONLines[event](lines, MaxLineSize(maxLineSize))
+
+	var decoded []event
+	var decodeErrs []*JSONLineError
+	for values != nil || errs != nil {
+		select {
+		case value, ok := <-values:
+			if !ok {
+				values = nil
+				continue
+			}
+			decoded = append(decoded, value)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			var lineErr *JSONLineError
+			require.ErrorAs(t, err, &lineErr)
+			decodeErrs = append(decodeErrs, lineErr)
+		}
+	}
+
+	assert.Equal(t, []event{{Name: "a", Value: 1}, {Value: 2}}, decoded)
+	require.Len(t, decodeErrs, 3)
+	assert.Equal(t, 2, decodeErrs[0].Number)
+	assert.Equal(t, "not json", decodeErrs[0].Line)
+	assert.Equal(t, 3, decodeErrs[1].Number)
+	assert.Equal(t, `{"name"`, decodeErrs[1].Line)
+	assert.Equal(t, 5, decodeErrs[2].Number)
+	assert.Equal(t, `{"name":`, decodeErrs[2].Line)
+	assert.EqualError(t, decodeErrs[2], "decoding JSON line 5: unexpected end of JSON input")
+}
+
+func Test_DecodeJSONLines_longLine(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	const valueSize = 2 * defaultMaxLineSize
+	cmd := exec.Command("sh", "-c",
+		`printf '{"data":"'; head -c 2097152 /dev/zero | tr '\0' a; printf '"}\n'`)
+	// The line is streamed as three chunks with the default maximum line size.
+	process, err := New().StartProcess(cmd)
+	require.NoError(t, err)
+
+	values, errs := DecodeJSONLines[struct{ Data string }](process.Stdout())
+	stderrLines := process.Stderr()
+
+	var decoded []string
+	for values != nil || errs != nil || stderrLines != nil {
+		select {
+		case value, ok := <-values:
+			if !ok {
+				values = nil
+				continue
+			}
+			decoded = append(decoded, value.Data)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			t.Error(err)
+		case line, ok := <-stderrLines:
+			if !ok {
+				stderrLines = nil
+				continue
+			}
+			t.Error(line)
+		}
+	}
+
+	require.NoError(t, process.Wait())
+	require.Len(t, decoded, 1)
+	assert.Equal(t, strings.Repeat("a", valueSize), decoded[0])
+}
@@ -6,6 +6,7 @@ package command
 
 import (
 	io "io"
+	os "os"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -106,3 +107,153 @@ func (mr *MockexecCmdMockRecorder) Wait() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Wait", reflect.TypeOf((*MockexecCmd)(nil).Wait))
 }
+
+// MockprocessCmd is a mock of processCmd interface.
+type MockprocessCmd struct {
+	ctrl     *gomock.Controller
+	recorder *MockprocessCmdMockRecorder
+}
+
+// MockprocessCmdMockRecorder is the mock recorder for MockprocessCmd.
+type MockprocessCmdMockRecorder struct {
+	mock *MockprocessCmd
+}
+
+// NewMockprocessCmd creates a new mock instance.
+func NewMockprocessCmd(ctrl *gomock.Controller) *MockprocessCmd {
+	mock := &MockprocessCmd{ctrl: ctrl}
+	mock.recorder = &MockprocessCmdMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockprocessCmd) EXPECT() *MockprocessCmdMockRecorder {
+	return m.recorder
+}
+
+// CombinedOutput mocks base method.
+func (m *MockprocessCmd) CombinedOutput() ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CombinedOutput")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CombinedOutput indicates an expected call of CombinedOutput.
+func (mr *MockprocessCmdMockRecorder) CombinedOutput() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CombinedOutput", reflect.TypeOf((*MockprocessCmd)(nil).CombinedOutput))
+}
+
+// PID mocks base method.
+func (m *MockprocessCmd) PID() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PID")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// PID indicates an expected call of PID.
+func (mr *MockprocessCmdMockRecorder) PID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PID", reflect.TypeOf((*MockprocessCmd)(nil).PID))
+}
+
+// SetOutput mocks base method.
+func (m *MockprocessCmd) SetOutput(writer io.Writer) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetOutput", writer)
+}
+
+// SetOutput indicates an expected call of SetOutput.
+func (mr *MockprocessCmdMockRecorder) SetOutput(writer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOutput", reflect.TypeOf((*MockprocessCmd)(nil).SetOutput), writer)
+}
+
+// Signal mocks base method.
+func (m *MockprocessCmd) Signal(signal os.Signal) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Signal", signal)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Signal indicates an expected call of Signal.
+func (mr *MockprocessCmdMockRecorder) Signal(signal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Signal", reflect.TypeOf((*MockprocessCmd)(nil).Signal), signal)
+}
+
+// Start mocks base method.
+func (m *MockprocessCmd) Start() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockprocessCmdMockRecorder) Start() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockprocessCmd)(nil).Start))
+}
+
+// State mocks base method.
+func (m *MockprocessCmd) State() *os.ProcessState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "State")
+	ret0, _ := ret[0].(*os.ProcessState)
+	return ret0
+}
+
+// State indicates an expected call of State.
+func (mr *MockprocessCmdMockRecorder) State() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockprocessCmd)(nil).State))
+}
+
+// StderrPipe mocks base method.
+func (m *MockprocessCmd) StderrPipe() (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StderrPipe")
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StderrPipe indicates an expected call of StderrPipe.
+func (mr *MockprocessCmdMockRecorder) StderrPipe() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StderrPipe", reflect.TypeOf((*MockprocessCmd)(nil).StderrPipe))
+}
+
+// StdoutPipe mocks base method.
+func (m *MockprocessCmd) StdoutPipe() (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StdoutPipe")
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StdoutPipe indicates an expected call of StdoutPipe.
+func (mr *MockprocessCmdMockRecorder) StdoutPipe() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StdoutPipe", reflect.TypeOf((*MockprocessCmd)(nil).StdoutPipe))
+}
+
+// Wait mocks base method.
+func (m *MockprocessCmd) Wait() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Wait")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Wait indicates an expected call of Wait.
+func (mr *MockprocessCmdMockRecorder) Wait() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Wait", reflect.TypeOf((*MockprocessCmd)(nil).Wait))
+}
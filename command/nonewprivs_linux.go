@@ -0,0 +1,28 @@
+package command
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// startWithNoNewPrivileges starts the command from an operating system
+// thread with the no_new_privs attribute set, which is inherited by the
+// command. The thread is never unlocked so it is terminated once the
+// goroutine exits, since the attribute cannot be unset.
+func startWithNoNewPrivileges(cmd *exec.Cmd) error {
+	errCh := make(chan error)
+	go func() {
+		runtime.LockOSThread()
+		const enabled = 1
+		err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, enabled, 0, 0, 0)
+		if err != nil {
+			errCh <- fmt.Errorf("setting no new privileges: %w", err)
+			return
+		}
+		errCh <- cmd.Start()
+	}()
+	return <-errCh
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package command
+
+import (
+	"errors"
+	"os/exec"
+)
+
+var ErrNoNewPrivilegesNotSupported = errors.New("no new privileges is only supported on Linux")
+
+func startWithNoNewPrivileges(*exec.Cmd) error {
+	return ErrNoNewPrivilegesNotSupported
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"io"
+	"time"
+)
+
+type options struct {
+	outputHead  int
+	outputTail  int
+	timeout     time.Duration
+	rlimits     []rlimit
+	maxLineSize int
+	stdoutTee   io.Writer
+	stderrTee   io.Writer
+
+	credential         *credential
+	credentialFromFile string
+	envAllowlist       []string
+	workingDir         string
+	noNewPrivileges    bool
+
+	init  *Init
+	audit *auditOptions
+}
+
+func newOptions(setters []OptionSetter) (o options) {
+	o.maxLineSize = defaultMaxLineSize
+	for _, setter := range setters {
+		setter(&o)
+	}
+	return o
+}
+
+// OptionSetter sets an option for a command invocation.
+type OptionSetter func(o *options)
+
+// MaxOutput limits the output captured by Run and RunWithOptions to
+// the first head bytes and the last tail bytes, with a truncation marker
+// in between. Either value can be zero to only keep the head or the tail.
+// If both are zero, which is the default, the output is not limited.
+// Negative values are treated as zero.
+func MaxOutput(head, tail int) OptionSetter {
+	return func(o *options) {
+		o.outputHead = max(head, 0)
+		o.outputTail = max(tail, 0)
+	}
+}
+
+// Timeout kills the command if it is still running after
+// the timeout given. A zero timeout, the default, disables it.
+// Descendant processes of the command are not killed, and output
+// pipes they keep open are closed after a second of inactivity,
+// so a started process is done shortly after the timeout.
+func Timeout(timeout time.Duration) OptionSetter {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// MaxLineSize sets the maximum size in bytes of a line streamed to
+// a channel, excluding its line ending, and defaults to 1MB. A size
+// of zero or less sets the default size.
+// A line longer than this size is streamed as consecutive chunks of
+// this size followed by a last chunk with its remaining bytes, each
+// streamed as a separate line. The chunks carry no continuation marker,
+// so a line of exactly this size may be followed by a continuation chunk.
+// DecodeJSONLines reassembles the chunks of JSON lines.
+func MaxLineSize(size int) OptionSetter {
+	return func(o *options) {
+		if size <= 0 {
+			size = defaultMaxLineSize
+		}
+		o.maxLineSize = size
+	}
+}
+
+// TeeOutput writes the raw stdout and stderr output of a command
+// started with Start, StartProcess, StartReady or StartRaw to the
+// writers given, in addition to streaming it. Either writer can be nil
+// to not copy its output. The writers are written to from the goroutines
+// streaming the output, and a slow writer slows down the streaming.
+func TeeOutput(stdout, stderr io.Writer) OptionSetter {
+	return func(o *options) {
+		o.stdoutTee = stdout
+		o.stderrTee = stderr
+	}
+}
@@ -0,0 +1,68 @@
+package command
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// pipeIdleTimeout is the duration a pipe can be idle once the process
+// exited, before it gets closed.
+const pipeIdleTimeout = time.Second
+
+// pipeReader wraps the read end of an output pipe and records since
+// when it is blocked reading, to detect when it is idle.
+type pipeReader struct {
+	io.ReadCloser
+	// readingSince is the Unix nanoseconds time the current Read call
+	// started at, or 0 if no Read call is in progress.
+	readingSince atomic.Int64
+}
+
+func newPipeReader(readCloser io.ReadCloser) *pipeReader {
+	return &pipeReader{ReadCloser: readCloser}
+}
+
+func (p *pipeReader) Read(b []byte) (n int, err error) {
+	p.readingSince.Store(time.Now().UnixNano())
+	defer p.readingSince.Store(0)
+	return p.ReadCloser.Read(b)
+}
+
+// idleFor returns for how long the pipe has been blocked reading
+// at the time given, or 0 if it is not reading.
+func (p *pipeReader) idleFor(now time.Time) time.Duration {
+	readingSince := p.readingSince.Load()
+	if readingSince == 0 {
+		return 0
+	}
+	return now.Sub(time.Unix(0, readingSince))
+}
+
+// closeIdlePipes closes each pipe once it has been blocked reading
+// for pipeIdleTimeout, until the drained channel is closed.
+// A pipe not being read, for example because its consumer is slow,
+// is not closed so its buffered data is not lost.
+func closeIdlePipes(drained <-chan struct{}, pipes ...*pipeReader) {
+	timer := time.NewTimer(pipeIdleTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-drained:
+			return
+		case now := <-timer.C:
+			next := pipeIdleTimeout
+			for _, pipe := range pipes {
+				idle := pipe.idleFor(now)
+				if idle >= pipeIdleTimeout {
+					_ = pipe.Close()
+					continue
+				}
+				if idle > 0 {
+					next = min(next, pipeIdleTimeout-idle)
+				}
+			}
+			timer.Reset(next)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// StageStatus contains the status of a pipeline stage once it exited.
+type StageStatus struct {
+	// ExitCode is the exit code of the stage command,
+	// or -1 if it did not exit normally.
+	ExitCode int
+	// Err is the error returned waiting for the stage command.
+	Err error
+	// Usage is the resource usage of the stage command.
+	Usage Usage
+}
+
+// Pipeline is a handle on started commands with the standard output
+// of each command connected to the standard input of the next one.
+type Pipeline struct {
+	cmds     []cmdAdapter
+	stdout   <-chan string
+	stderr   <-chan string
+	done     chan struct{}
+	statuses []StageStatus
+	waitErr  error
+	timedOut bool
+}
+
+// PipelineSettings are the settings specific to pipelines.
+type PipelineSettings struct {
+	// Pipefail makes the pipeline fail if any of its commands fails,
+	// instead of only if its last command fails. It is disabled by
+	// default, matching the default shell behavior.
+	Pipefail bool
+}
+
+var (
+	ErrPipelineEmpty       = errors.New("pipeline has no command")
+	ErrPipelineStageFailed = errors.New("pipeline stage failed")
+	ErrInputAlreadySet     = errors.New("input already set")
+)
+
+// StartPipeline starts the commands given, connecting the stdout of
+// each command to the stdin of the next command, like a shell pipeline.
+// The stdout of the last command is streamed to the Stdout channel,
+// and the stderr of all the commands is streamed to the Stderr channel.
+// Both channels are closed once drained, and must be read until closed
+// for the pipeline to be marked as done.
+// Only the stdin of the first command can be set by the caller, and an
+// error wrapping ErrInputAlreadySet or ErrOutputAlreadySet is returned
+// if another standard stream of the commands is already set.
+// All the commands are killed if the context is canceled.
+// The settings given are specific to the pipeline, and the
+// MaxOutput and TeeOutput options are ignored.
+// The options given modify each command, as described on Cmder.
+func (c *Cmder) StartPipeline(ctx context.Context, cmds []*exec.Cmd,
+	settings PipelineSettings, setters ...OptionSetter) (pipeline *Pipeline, err error) {
+	if len(cmds) == 0 {
+		return nil, ErrPipelineEmpty
+	}
+
+	options := c.newOptions(setters)
+	adapters := make([]cmdAdapter, len(cmds))
+	for i, cmd := range cmds {
+		switch {
+		case i > 0 && cmd.Stdin != nil:
+			return nil, fmt.Errorf("stage %d: %w: stdin", i, ErrInputAlreadySet)
+		case cmd.Stdout != nil:
+			return nil, fmt.Errorf("stage %d: %w: stdout", i, ErrOutputAlreadySet)
+		case cmd.Stderr != nil:
+			return nil, fmt.Errorf("stage %d: %w: stderr", i, ErrOutputAlreadySet)
+		}
+		adapters[i], err = newCmdAdapter(cmd, options)
+		if err != nil {
+			return nil, fmt.Errorf("stage %d: %w", i, err)
+		}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if options.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+	}
+
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("creating stdout pipe: %w", err)
+	}
+	stderrReader, stderrWriter, err := os.Pipe()
+	if err != nil {
+		cancel()
+		_ = stdoutReader.Close()
+		_ = stdoutWriter.Close()
+		return nil, fmt.Errorf("creating stderr pipe: %w", err)
+	}
+
+	// parentFiles are the pipe ends only used by the child processes
+	// and which must be closed in this process once they are started.
+	parentFiles := []*os.File{stdoutWriter, stderrWriter}
+	closeParentFiles := func() {
+		for _, file := range parentFiles {
+			_ = file.Close()
+		}
+	}
+
+	for i, cmd := range cmds {
+		cmd.Stderr = stderrWriter
+		if i == len(cmds)-1 {
+			cmd.Stdout = stdoutWriter
+			break
+		}
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			cancel()
+			closeParentFiles()
+			_ = stdoutReader.Close()
+			_ = stderrReader.Close()
+			return nil, fmt.Errorf("creating pipe for stage %d: %w", i, err)
+		}
+		parentFiles = append(parentFiles, reader, writer)
+		cmd.Stdout = writer
+		cmds[i+1].Stdin = reader
+	}
+
+	stop := make(chan struct{})
+	stdoutReady := make(chan struct{})
+	stdoutLines := make(chan string)
+	stdoutDone := make(chan struct{})
+	go streamToChannel(stdoutReady, stop, stdoutDone, stdoutReader, stdoutLines, options.maxLineSize)
+	stderrReady := make(chan struct{})
+	stderrLines := make(chan string)
+	stderrDone := make(chan struct{})
+	go streamToChannel(stderrReady, stop, stderrDone, stderrReader, stderrLines, options.maxLineSize)
+
+	for i, adapter := range adapters {
+		err = adapter.Start()
+		if err == nil {
+			err = applyRLimitsOrKill(adapter, options.rlimits)
+		}
+		if err == nil {
+			continue
+		}
+		cancel()
+		for _, startedCmd := range adapters[:i] {
+			_ = startedCmd.Process.Kill()
+			_ = startedCmd.Wait()
+		}
+		closeParentFiles()
+		_ = stdoutReader.Close()
+		_ = stderrReader.Close()
+		close(stop)
+		<-stdoutDone
+		<-stderrDone
+		return nil, fmt.Errorf("starting stage %d: %w", i, err)
+	}
+	closeParentFiles()
+
+	pipeline = &Pipeline{
+		cmds:     adapters,
+		stdout:   stdoutLines,
+		stderr:   stderrLines,
+		done:     make(chan struct{}),
+		statuses: make([]StageStatus, len(cmds)),
+	}
+
+	go func() {
+		defer cancel()
+		defer close(pipeline.done)
+
+		// The pipeline is only interrupted if the commands are killed
+		// before they all exited, since the context can be canceled
+		// concurrently with the commands exiting on their own.
+		var mutex sync.Mutex
+		exited, wasInterrupted := false, false
+		waited := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				mutex.Lock()
+				defer mutex.Unlock()
+				if exited {
+					return
+				}
+				for _, cmd := range cmds {
+					_ = cmd.Process.Kill()
+				}
+				wasInterrupted = true
+			case <-waited:
+			}
+		}()
+
+		pipeline.waitStages()
+		mutex.Lock()
+		exited = true
+		mutex.Unlock()
+		close(waited)
+
+		<-stdoutDone
+		close(stdoutLines)
+		<-stderrDone
+		close(stderrLines)
+		close(stop)
+		_ = stdoutReader.Close()
+		_ = stderrReader.Close()
+
+		pipeline.waitErr = pipelineError(pipeline.statuses, settings.Pipefail)
+		if wasInterrupted {
+			ctxErr := ctx.Err()
+			pipeline.waitErr = contextError(ctxErr, pipeline.waitErr, options.timeout)
+			pipeline.timedOut = errors.Is(ctxErr, context.DeadlineExceeded) &&
+				options.timeout > 0
+		}
+	}()
+
+	return pipeline, nil
+}
+
+func (p *Pipeline) waitStages() {
+	var wg sync.WaitGroup
+	for i, cmd := range p.cmds {
+		wg.Add(1)
+		go func(i int, cmd cmdAdapter) {
+			defer wg.Done()
+			err := cmd.Wait()
+			p.statuses[i] = StageStatus{
+				ExitCode: cmd.ProcessState.ExitCode(),
+				Err:      err,
+				Usage:    usageFromState(cmd.ProcessState),
+			}
+		}(i, cmd)
+	}
+	wg.Wait()
+}
+
+// pipelineError returns the error of the last stage, or the error of
+// the last failing stage if pipefail is enabled.
+func pipelineError(statuses []StageStatus, pipefail bool) (err error) {
+	lastIndex := len(statuses) - 1
+	for i := lastIndex; i >= 0; i-- {
+		if statuses[i].Err != nil {
+			return fmt.Errorf("%w: stage %d: %w", ErrPipelineStageFailed, i, statuses[i].Err)
+		} else if !pipefail {
+			break
+		}
+	}
+	return nil
+}
+
+func contextError(ctxErr, err error, timeout time.Duration) error {
+	if errors.Is(ctxErr, context.DeadlineExceeded) && timeout > 0 {
+		return wrapTimeoutError(err, timeout)
+	}
+	if err == nil {
+		return ctxErr
+	}
+	return fmt.Errorf("%w: %w", ctxErr, err)
+}
+
+// Stdout returns the channel of stdout lines of the last command,
+// which is closed once the stdout stream is drained.
+func (p *Pipeline) Stdout() <-chan string {
+	return p.stdout
+}
+
+// Stderr returns the channel of stderr lines of all the commands,
+// which is closed once the stderr stream is drained.
+func (p *Pipeline) Stderr() <-chan string {
+	return p.stderr
+}
+
+// Done returns a channel closed once all the commands have exited
+// and the output streams are drained.
+func (p *Pipeline) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait blocks until the pipeline is done and returns its error.
+// Without the Pipefail setting, the error is the error of the last
+// command only. With it, it is the error of the last command failing.
+// The error wraps ErrPipelineStageFailed if a command failed, and
+// ErrTimedOut or the context error if the pipeline was interrupted.
+// It can be called multiple times and from multiple goroutines.
+func (p *Pipeline) Wait() error {
+	<-p.done
+	return p.waitErr
+}
+
+// TimedOut returns true if the pipeline was killed because of the
+// Timeout option. It blocks until the pipeline is done.
+func (p *Pipeline) TimedOut() bool {
+	<-p.done
+	return p.timedOut
+}
+
+// Stages returns the status of each command of the pipeline,
+// in the same order as the commands given. It blocks until
+// the pipeline is done.
+func (p *Pipeline) Stages() (statuses []StageStatus) {
+	<-p.done
+	statuses = make([]StageStatus, len(p.statuses))
+	copy(statuses, p.statuses)
+	return statuses
+}
@@ -0,0 +1,173 @@
+package command
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAllLines(t *testing.T, stdout, stderr <-chan string) (
+	stdoutLines, stderrLines []string) {
+	t.Helper()
+	for stdout != nil || stderr != nil {
+		select {
+		case line, ok := <-stdout:
+			if !ok {
+				stdout = nil
+				continue
+			}
+			stdoutLines = append(stdoutLines, line)
+		case line, ok := <-stderr:
+			if !ok {
+				stderr = nil
+				continue
+			}
+			stderrLines = append(stderrLines, line)
+		}
+	}
+	return stdoutLines, stderrLines
+}
+
+func Test_Cmder_StartPipeline(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline, err := New().StartPipeline(context.Background(), nil, PipelineSettings{})
+		assert.ErrorIs(t, err, ErrPipelineEmpty)
+		assert.Nil(t, pipeline)
+	})
+
+	t.Run("start error", func(t *testing.T) {
+		t.Parallel()
+
+		cmds := []*exec.Cmd{
+			exec.Command("sh", "-c", "echo hello"),
+			exec.Command("/non/existent/binary"),
+		}
+		pipeline, err := New().StartPipeline(context.Background(), cmds, PipelineSettings{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "starting stage 1: ")
+		assert.Nil(t, pipeline)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		cmds := []*exec.Cmd{
+			exec.Command("sh", "-c", "printf 'a\\nb\\nc\\n'; echo first >&2"),
+			exec.Command("tr", "a-z", "A-Z"),
+			exec.Command("sh", "-c", "grep -v B; echo last >&2"),
+		}
+		pipeline, err := New().StartPipeline(context.Background(), cmds, PipelineSettings{})
+		require.NoError(t, err)
+
+		stdoutLines, stderrLines := readAllLines(t, pipeline.Stdout(), pipeline.Stderr())
+
+		require.NoError(t, pipeline.Wait())
+		assert.Equal(t, []string{"A", "C"}, stdoutLines)
+		assert.ElementsMatch(t, []string{"first", "last"}, stderrLines)
+		stages := pipeline.Stages()
+		require.Len(t, stages, len(cmds))
+		for _, stage := range stages {
+			assert.Equal(t, 0, stage.ExitCode)
+			assert.NoError(t, stage.Err)
+		}
+	})
+
+	t.Run("pipefail", func(t *testing.T) {
+		t.Parallel()
+
+		newCmds := func() []*exec.Cmd {
+			return []*exec.Cmd{
+				exec.Command("sh", "-c", "echo hello; exit 3"),
+				exec.Command("cat"),
+			}
+		}
+
+		pipeline, err := New().StartPipeline(context.Background(), newCmds(), PipelineSettings{})
+		require.NoError(t, err)
+		stdoutLines, _ := readAllLines(t, pipeline.Stdout(), pipeline.Stderr())
+		assert.NoError(t, pipeline.Wait())
+		assert.Equal(t, []string{"hello"}, stdoutLines)
+		assert.Equal(t, 3, pipeline.Stages()[0].ExitCode)
+
+		pipeline, err = New().StartPipeline(context.Background(), newCmds(),
+			PipelineSettings{Pipefail: true})
+		require.NoError(t, err)
+		_, _ = readAllLines(t, pipeline.Stdout(), pipeline.Stderr())
+		err = pipeline.Wait()
+		require.ErrorIs(t, err, ErrPipelineStageFailed)
+		assert.Contains(t, err.Error(), "stage 0: exit status 3")
+	})
+
+	t.Run("streams already set", func(t *testing.T) {
+		t.Parallel()
+
+		cmds := []*exec.Cmd{
+			exec.Command("echo", "hello"),
+			exec.Command("cat"),
+		}
+		cmds[0].Stdin = strings.NewReader("input")
+		cmds[1].Stdin = strings.NewReader("input")
+
+		pipeline, err := New().StartPipeline(context.Background(), cmds, PipelineSettings{})
+		assert.Nil(t, pipeline)
+		require.ErrorIs(t, err, ErrInputAlreadySet)
+		assert.EqualError(t, err, "stage 1: input already set: stdin")
+
+		cmds[1].Stdin = nil
+		cmds[1].Stderr = io.Discard
+		pipeline, err = New().StartPipeline(context.Background(), cmds, PipelineSettings{})
+		assert.Nil(t, pipeline)
+		require.ErrorIs(t, err, ErrOutputAlreadySet)
+		assert.EqualError(t, err, "stage 1: output already set: stderr")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cmds := []*exec.Cmd{
+			exec.Command("sleep", "10"),
+			exec.Command("cat"),
+		}
+		pipeline, err := New().StartPipeline(ctx, cmds, PipelineSettings{})
+		require.NoError(t, err)
+		cancel()
+
+		_, _ = readAllLines(t, pipeline.Stdout(), pipeline.Stderr())
+		err = pipeline.Wait()
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, pipeline.TimedOut())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		cmds := []*exec.Cmd{
+			exec.Command("sleep", "10"),
+			exec.Command("cat"),
+		}
+		pipeline, err := New().StartPipeline(context.Background(), cmds, PipelineSettings{},
+			Timeout(100*time.Millisecond))
+		require.NoError(t, err)
+
+		_, _ = readAllLines(t, pipeline.Stdout(), pipeline.Stderr())
+		err = pipeline.Wait()
+		assert.ErrorIs(t, err, ErrTimedOut)
+		assert.True(t, pipeline.TimedOut())
+	})
+}
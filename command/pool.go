@@ -0,0 +1,119 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// PoolSettings are the settings for a Pool.
+type PoolSettings struct {
+	// MaxParallel is the maximum number of commands running
+	// at the same time, and defaults to the number of CPUs.
+	MaxParallel int
+	// JobTimeout is the timeout for each command, and
+	// defaults to zero which means no timeout.
+	JobTimeout time.Duration
+	// FailFast cancels the context of the running commands and
+	// skips the commands not started yet once a command fails.
+	// It defaults to false, where all the commands are run.
+	FailFast bool
+}
+
+func (s *PoolSettings) setDefaults() {
+	if s.MaxParallel == 0 {
+		s.MaxParallel = runtime.NumCPU()
+	}
+}
+
+var ErrPoolSettingsInvalid = errors.New("pool settings are not valid")
+
+func (s *PoolSettings) validate() (err error) {
+	switch {
+	case s.MaxParallel < 0:
+		return fmt.Errorf("%w: max parallel %d cannot be negative",
+			ErrPoolSettingsInvalid, s.MaxParallel)
+	case s.JobTimeout < 0:
+		return fmt.Errorf("%w: job timeout %s cannot be negative",
+			ErrPoolSettingsInvalid, s.JobTimeout)
+	}
+	return nil
+}
+
+// Pool runs commands concurrently with a bounded parallelism.
+type Pool struct {
+	runner   Runner
+	settings PoolSettings
+}
+
+// NewPool creates a pool running commands with the runner given.
+// It returns an error wrapping ErrPoolSettingsInvalid if a setting
+// is negative.
+func NewPool(runner Runner, settings PoolSettings) (pool *Pool, err error) {
+	settings.setDefaults()
+	err = settings.validate()
+	if err != nil {
+		return nil, err
+	}
+	return &Pool{
+		runner:   runner,
+		settings: settings,
+	}, nil
+}
+
+var ErrJobSkipped = errors.New("job skipped")
+
+// Run creates and runs a command for each of the jobs given, with at
+// most MaxParallel commands running at the same time. Each job is given
+// a context canceled if the context given is canceled, or if another
+// command failed and FailFast is enabled; it should be used to create
+// the command with exec.CommandContext.
+// It returns a slice of results and a slice of errors with the same
+// indexing and order as the jobs, meaning that some errors might be nil
+// or not. You should ensure to iterate over the errors and check each
+// of them. Jobs not started because the context is canceled have an
+// error wrapping ErrJobSkipped and the cancellation cause.
+func (p *Pool) Run(ctx context.Context, jobs []func(ctx context.Context) *exec.Cmd,
+	setters ...OptionSetter) (results []Result, errs []error) {
+	results = make([]Result, len(jobs))
+	errs = make([]error, len(jobs))
+
+	if p.settings.JobTimeout > 0 {
+		setters = append(setters[:len(setters):len(setters)], Timeout(p.settings.JobTimeout))
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	semaphore := make(chan struct{}, p.settings.MaxParallel)
+	var wg sync.WaitGroup
+	for i, newCmd := range jobs {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			results[i] = Result{ExitCode: -1}
+			errs[i] = fmt.Errorf("%w: %w", ErrJobSkipped, context.Cause(ctx))
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, newCmd func(ctx context.Context) *exec.Cmd) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[i], errs[i] = p.runner.RunWithOptions(newCmd(ctx), setters...)
+			if errs[i] != nil && p.settings.FailFast {
+				cancel(fmt.Errorf("job %d failed: %w", i, errs[i]))
+			}
+		}(i, newCmd)
+	}
+	wg.Wait()
+
+	return results, errs
+}
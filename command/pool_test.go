@@ -0,0 +1,151 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type concurrencyRunner struct {
+	mutex      sync.Mutex
+	running    int
+	maxRunning int
+}
+
+func (r *concurrencyRunner) RunWithOptions(cmd *exec.Cmd, _ ...OptionSetter) (Result, error) {
+	r.mutex.Lock()
+	r.running++
+	r.maxRunning = max(r.maxRunning, r.running)
+	r.mutex.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	r.mutex.Lock()
+	r.running--
+	r.mutex.Unlock()
+
+	return Result{Output: cmd.Args[1]}, nil
+}
+
+func Test_Pool_Run(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bounded and ordered", func(t *testing.T) {
+		t.Parallel()
+
+		const jobsCount = 20
+		jobs := make([]func(ctx context.Context) *exec.Cmd, jobsCount)
+		for i := range jobs {
+			arg := fmt.Sprint(i)
+			jobs[i] = func(ctx context.Context) *exec.Cmd {
+				return exec.CommandContext(ctx, "echo", arg)
+			}
+		}
+
+		runner := &concurrencyRunner{}
+		pool, err := NewPool(runner, PoolSettings{MaxParallel: 3})
+		require.NoError(t, err)
+
+		results, errs := pool.Run(context.Background(), jobs)
+
+		require.Len(t, results, jobsCount)
+		require.Len(t, errs, jobsCount)
+		for i := range jobs {
+			assert.NoError(t, errs[i])
+			assert.Equal(t, fmt.Sprint(i), results[i].Output)
+		}
+		assert.Equal(t, 3, runner.maxRunning)
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		t.Parallel()
+
+		fake, err := NewFake([]FakeCommand{
+			{Path: "fail", ExitCode: 1},
+			{Path: "ok"},
+		})
+		require.NoError(t, err)
+		pool, err := NewPool(fake, PoolSettings{MaxParallel: 1, FailFast: true})
+		require.NoError(t, err)
+
+		jobs := []func(ctx context.Context) *exec.Cmd{
+			func(ctx context.Context) *exec.Cmd { return exec.CommandContext(ctx, "ok") },
+			func(ctx context.Context) *exec.Cmd { return exec.CommandContext(ctx, "fail") },
+			func(ctx context.Context) *exec.Cmd { return exec.CommandContext(ctx, "ok") },
+		}
+
+		results, errs := pool.Run(context.Background(), jobs)
+
+		assert.NoError(t, errs[0])
+		assert.Equal(t, 0, results[0].ExitCode)
+		var exitErr *FakeExitError
+		assert.ErrorAs(t, errs[1], &exitErr)
+		assert.Equal(t, 1, results[1].ExitCode)
+		assert.ErrorIs(t, errs[2], ErrJobSkipped)
+		assert.ErrorAs(t, errs[2], &exitErr)
+		assert.Equal(t, -1, results[2].ExitCode)
+	})
+
+	t.Run("collect all with timeout", func(t *testing.T) {
+		t.Parallel()
+
+		fake, err := NewFake([]FakeCommand{
+			{Path: "fail", ExitCode: 1},
+			{Path: "slow", Delay: time.Hour},
+			{Path: "ok"},
+		})
+		require.NoError(t, err)
+		pool, err := NewPool(fake, PoolSettings{MaxParallel: 2, JobTimeout: time.Millisecond})
+		require.NoError(t, err)
+
+		jobs := []func(ctx context.Context) *exec.Cmd{
+			func(ctx context.Context) *exec.Cmd { return exec.CommandContext(ctx, "fail") },
+			func(ctx context.Context) *exec.Cmd { return exec.CommandContext(ctx, "slow") },
+			func(ctx context.Context) *exec.Cmd { return exec.CommandContext(ctx, "ok") },
+		}
+
+		results, errs := pool.Run(context.Background(), jobs)
+
+		var exitErr *FakeExitError
+		assert.ErrorAs(t, errs[0], &exitErr)
+		assert.ErrorIs(t, errs[1], ErrTimedOut)
+		assert.True(t, results[1].TimedOut)
+		assert.NoError(t, errs[2])
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		pool, err := NewPool(&concurrencyRunner{}, PoolSettings{})
+		require.NoError(t, err)
+		jobs := []func(ctx context.Context) *exec.Cmd{
+			func(ctx context.Context) *exec.Cmd { return exec.CommandContext(ctx, "echo", "x") },
+		}
+
+		_, errs := pool.Run(ctx, jobs)
+
+		assert.ErrorIs(t, errs[0], ErrJobSkipped)
+		assert.True(t, errors.Is(errs[0], context.Canceled))
+	})
+}
+
+func Test_NewPool(t *testing.T) {
+	t.Parallel()
+
+	pool, err := NewPool(nil, PoolSettings{MaxParallel: -1})
+
+	assert.Nil(t, pool)
+	assert.ErrorIs(t, err, ErrPoolSettingsInvalid)
+	assert.EqualError(t, err, "pool settings are not valid: "+
+		"max parallel -1 cannot be negative")
+}
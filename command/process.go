@@ -0,0 +1,237 @@
+package command
+
+import (
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Process is a handle on a started command.
+type Process struct {
+	cmd      processCmd
+	stdout   <-chan string
+	stderr   <-chan string
+	done     chan struct{}
+	waitErr  error
+	timedOut bool
+}
+
+// StartProcess launches a command and returns a process handle
+// streaming its stdout and stderr lines to channels.
+// The stdout and stderr channels are closed once their respective
+// stream is fully drained, and must be read until closed for
+// the process to be marked as done.
+// The MaxOutput option is ignored.
+// The options given modify the command, as described on Cmder.
+func (c *Cmder) StartProcess(cmd *exec.Cmd, setters ...OptionSetter) (
+	process *Process, err error) {
+	options := c.newOptions(setters)
+	adapter, err := newCmdAdapter(cmd, options)
+	if err != nil {
+		return nil, err
+	}
+	return startProcess(adapter, options)
+}
+
+func startProcess(cmd processCmd, options options) (process *Process, err error) {
+	stdoutLines := make(chan string)
+	stderrLines := make(chan string)
+	process = &Process{
+		cmd:    cmd,
+		stdout: stdoutLines,
+		stderr: stderrLines,
+		done:   make(chan struct{}),
+	}
+	err = process.start(options,
+		outputStream{
+			stream: func(ready chan<- struct{}, stop <-chan struct{}, done chan<- struct{}, stream io.Reader) {
+				streamToChannel(ready, stop, done, stream, stdoutLines, options.maxLineSize)
+			},
+			close: func() { close(stdoutLines) },
+		},
+		outputStream{
+			stream: func(ready chan<- struct{}, stop <-chan struct{}, done chan<- struct{}, stream io.Reader) {
+				streamToChannel(ready, stop, done, stream, stderrLines, options.maxLineSize)
+			},
+			close: func() { close(stderrLines) },
+		})
+	if err != nil {
+		return nil, err
+	}
+	return process, nil
+}
+
+// outputStream streams an output of a process, and closes
+// its output channel once the output is drained.
+type outputStream struct {
+	stream func(ready chan<- struct{}, stop <-chan struct{}, done chan<- struct{}, stream io.Reader)
+	close  func()
+}
+
+// start starts the command of the process and streams its stdout
+// and stderr outputs with the output streams given.
+func (p *Process) start(options options, stdoutStream, stderrStream outputStream) (err error) {
+	cmd := p.cmd
+	stop := make(chan struct{})
+	stdoutReady := make(chan struct{})
+	stdoutDone := make(chan struct{})
+	stderrReady := make(chan struct{})
+	stderrDone := make(chan struct{})
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stdout := newPipeReader(stdoutPipe)
+	stdoutReader := teeReader(stdout, options.stdoutTee)
+	go stdoutStream.stream(stdoutReady, stop, stdoutDone, stdoutReader)
+
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		_ = stdout.Close()
+		close(stop)
+		<-stdoutDone
+		return err
+	}
+	stderr := newPipeReader(stderrPipe)
+	stderrReader := teeReader(stderr, options.stderrTee)
+	go stderrStream.stream(stderrReady, stop, stderrDone, stderrReader)
+
+	err = cmd.Start()
+	if err != nil {
+		_ = stdout.Close()
+		_ = stderr.Close()
+		close(stop)
+		<-stdoutDone
+		<-stderrDone
+		return err
+	}
+
+	err = applyRLimitsOrKill(cmd, options.rlimits)
+	if err != nil {
+		_ = stdout.Close()
+		_ = stderr.Close()
+		close(stop)
+		<-stdoutDone
+		<-stderrDone
+		return err
+	}
+
+	stopTimeoutWatch := watchTimeout(cmd, options.timeout)
+
+	exited := make(chan struct{})
+	go func() {
+		p.waitErr = cmd.Wait()
+		p.timedOut = stopTimeoutWatch()
+		close(exited)
+	}()
+
+	drained := make(chan struct{})
+	go func() {
+		// Any remaining data after a stream error is discarded so
+		// the command does not block writing to a full pipe.
+		<-stdoutDone
+		_, _ = io.Copy(io.Discard, stdoutReader)
+		stdoutStream.close()
+		<-stderrDone
+		_, _ = io.Copy(io.Discard, stderrReader)
+		stderrStream.close()
+		close(drained)
+	}()
+
+	go func() {
+		<-exited
+		// A descendant process may have inherited the pipes and keep
+		// them open after the process exited, so idle pipes are closed
+		// to not wait for it.
+		closeIdlePipes(drained, stdout, stderr)
+		<-drained
+		_ = stdout.Close()
+		_ = stderr.Close()
+		if p.timedOut {
+			p.waitErr = wrapTimeoutError(p.waitErr, options.timeout)
+		}
+		close(stop)
+		close(p.done)
+	}()
+
+	return nil
+}
+
+func teeReader(reader io.Reader, writer io.Writer) io.Reader {
+	if writer == nil {
+		return reader
+	}
+	return io.TeeReader(reader, writer)
+}
+
+// PID returns the process ID of the process.
+func (p *Process) PID() int {
+	return p.cmd.PID()
+}
+
+// Signal sends a signal to the process.
+func (p *Process) Signal(signal os.Signal) error {
+	return p.cmd.Signal(signal)
+}
+
+// Stdout returns the channel of stdout lines, which is closed
+// once the stdout stream is drained.
+func (p *Process) Stdout() <-chan string {
+	return p.stdout
+}
+
+// Stderr returns the channel of stderr lines, which is closed
+// once the stderr stream is drained.
+func (p *Process) Stderr() <-chan string {
+	return p.stderr
+}
+
+// Done returns a channel closed once the process has exited
+// and its output streams are drained.
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Wait blocks until the process is done and returns its wait error.
+// If the process timed out, the error wraps ErrTimedOut.
+// It can be called multiple times and from multiple goroutines.
+func (p *Process) Wait() error {
+	<-p.done
+	return p.waitErr
+}
+
+// TimedOut returns true if the process was killed because
+// of the Timeout option. It blocks until the process is done.
+func (p *Process) TimedOut() bool {
+	<-p.done
+	return p.timedOut
+}
+
+// ExitCode returns the exit code of the process, or -1 if the
+// process has not exited yet or was terminated by a signal.
+func (p *Process) ExitCode() int {
+	select {
+	case <-p.done:
+	default:
+		return -1
+	}
+	return exitCode(p.cmd.State(), p.waitErr)
+}
+
+// Usage returns the resource usage of the process once it is done,
+// and an empty usage otherwise.
+func (p *Process) Usage() Usage {
+	return usageFromState(p.State())
+}
+
+// State returns the process state once the process is done,
+// and nil otherwise.
+func (p *Process) State() *os.ProcessState {
+	select {
+	case <-p.done:
+		return p.cmd.State()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,212 @@
+package command
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_startProcess(t *testing.T) {
+	t.Parallel()
+
+	errDummy := errors.New("dummy")
+
+	testCases := map[string]struct {
+		stdout        []string
+		stdoutPipeErr error
+		stderr        []string
+		stderrPipeErr error
+		startErr      error
+		waitErr       error
+		err           error
+	}{
+		"no output": {},
+		"success": {
+			stdout: []string{"hello", "world"},
+			stderr: []string{"some", "error"},
+		},
+		"stdout pipe error": {
+			stdoutPipeErr: errDummy,
+			err:           errDummy,
+		},
+		"stderr pipe error": {
+			stderrPipeErr: errDummy,
+			err:           errDummy,
+		},
+		"start error": {
+			startErr: errDummy,
+			err:      errDummy,
+		},
+		"wait error": {
+			stdout:  []string{"hello"},
+			waitErr: errDummy,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			stdout := linesToReadCloser(testCase.stdout)
+			stderr := linesToReadCloser(testCase.stderr)
+
+			mockCmd := NewMockprocessCmd(ctrl)
+
+			mockCmd.EXPECT().StdoutPipe().
+				Return(stdout, testCase.stdoutPipeErr)
+			if testCase.stdoutPipeErr == nil {
+				mockCmd.EXPECT().StderrPipe().Return(stderr, testCase.stderrPipeErr)
+				if testCase.stderrPipeErr == nil {
+					mockCmd.EXPECT().Start().Return(testCase.startErr)
+					if testCase.startErr == nil {
+						mockCmd.EXPECT().Wait().Return(testCase.waitErr)
+					}
+				}
+			}
+
+			process, err := startProcess(mockCmd, newOptions(nil))
+
+			if testCase.err != nil {
+				require.Error(t, err)
+				assert.Equal(t, testCase.err.Error(), err.Error())
+				assert.Nil(t, process)
+				return
+			}
+			require.NoError(t, err)
+
+			var stdoutLines, stderrLines []string
+			stdoutCh, stderrCh := process.Stdout(), process.Stderr()
+			for stdoutCh != nil || stderrCh != nil {
+				select {
+				case line, ok := <-stdoutCh:
+					if !ok {
+						stdoutCh = nil
+						continue
+					}
+					stdoutLines = append(stdoutLines, line)
+				case line, ok := <-stderrCh:
+					if !ok {
+						stderrCh = nil
+						continue
+					}
+					stderrLines = append(stderrLines, line)
+				}
+			}
+
+			<-process.Done()
+			for i := 0; i < 2; i++ {
+				err = process.Wait()
+				if testCase.waitErr != nil {
+					require.Error(t, err)
+					assert.Equal(t, testCase.waitErr.Error(), err.Error())
+				} else {
+					assert.NoError(t, err)
+				}
+			}
+
+			assert.Equal(t, testCase.stdout, stdoutLines)
+			assert.Equal(t, testCase.stderr, stderrLines)
+		})
+	}
+}
+
+func Test_Cmder_StartProcess(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	cmder := New()
+	cmd := exec.Command("sh", "-c", "echo hello; echo world >&2; exit 3")
+
+	process, err := cmder.StartProcess(cmd)
+	require.NoError(t, err)
+
+	assert.Positive(t, process.PID())
+	assert.Equal(t, -1, process.ExitCode())
+
+	var stdoutLines, stderrLines []string
+	for line := range process.Stdout() {
+		stdoutLines = append(stdoutLines, line)
+	}
+	for line := range process.Stderr() {
+		stderrLines = append(stderrLines, line)
+	}
+
+	err = process.Wait()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, process.ExitCode())
+	require.NotNil(t, process.State())
+	assert.Equal(t, []string{"hello"}, stdoutLines)
+	assert.Equal(t, []string{"world"}, stderrLines)
+}
+
+func Test_Cmder_Start_descendantHoldsPipes(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	cmder := New()
+	// The background sleep inherits the stdout and stderr pipes
+	// and keeps them open after the shell exits.
+	cmd := exec.Command("sh", "-c", "sleep 3 & echo hello")
+
+	startTime := time.Now()
+	stdoutLines, stderrLines, waitError, err := cmder.Start(cmd)
+	require.NoError(t, err)
+
+	var lines []string
+	for waitError != nil {
+		select {
+		case line := <-stdoutLines:
+			lines = append(lines, line)
+		case <-stderrLines:
+		case err = <-waitError:
+			waitError = nil
+		}
+	}
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hello"}, lines)
+	assert.Less(t, time.Since(startTime), 2*time.Second)
+}
+
+func Test_Cmder_StartProcess_timeoutDescendant(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	cmder := New()
+	cmd := exec.Command("sh", "-c", "sleep 3 & sleep 3")
+
+	startTime := time.Now()
+	process, err := cmder.StartProcess(cmd, Timeout(100*time.Millisecond))
+	require.NoError(t, err)
+
+	go func() {
+		for range process.Stderr() {
+		}
+	}()
+	for range process.Stdout() {
+	}
+
+	err = process.Wait()
+	require.ErrorIs(t, err, ErrTimedOut)
+	assert.True(t, process.TimedOut())
+	assert.Less(t, time.Since(startTime), 2*time.Second)
+}
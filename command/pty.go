@@ -0,0 +1,117 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+var ErrPTYNotSupported = errors.New("pseudo-terminal not supported on this platform")
+
+// WindowSize is the size of a terminal window in characters.
+type WindowSize struct {
+	Rows    uint16
+	Columns uint16
+}
+
+// PTYProcess is a handle on a command started attached to a
+// pseudo-terminal. Its Stdout channel streams the terminal output
+// lines, which includes both the stdout and stderr of the command,
+// and its Stderr channel is closed right away.
+type PTYProcess struct {
+	*Process
+	terminal *os.File
+}
+
+// StartPTY launches a command attached to a new pseudo-terminal with the
+// window size given, and streams the terminal output lines to the Stdout
+// channel of the process returned. This is only supported on Linux.
+// The stdin, stdout and stderr of the command must not be set.
+// The MaxOutput and TeeOutput options are ignored.
+// The options given modify the command, as described on Cmder.
+func (c *Cmder) StartPTY(cmd *exec.Cmd, size WindowSize,
+	setters ...OptionSetter) (process *PTYProcess, err error) {
+	options := c.newOptions(setters)
+	adapter, err := newCmdAdapter(cmd, options)
+	if err != nil {
+		return nil, err
+	}
+
+	terminal, tty, err := openPTY()
+	if err != nil {
+		return nil, fmt.Errorf("opening pseudo-terminal: %w", err)
+	}
+
+	err = setWindowSize(terminal, size)
+	if err != nil {
+		_ = terminal.Close()
+		_ = tty.Close()
+		return nil, fmt.Errorf("setting window size: %w", err)
+	}
+
+	cmd.Stdin = tty
+	cmd.Stdout = tty
+	cmd.Stderr = tty
+	cmd.SysProcAttr = ptySysProcAttr(cmd.SysProcAttr)
+
+	err = adapter.Start()
+	_ = tty.Close()
+	if err != nil {
+		_ = terminal.Close()
+		return nil, err
+	}
+
+	err = applyRLimitsOrKill(adapter, options.rlimits)
+	if err != nil {
+		_ = terminal.Close()
+		return nil, err
+	}
+
+	stop := make(chan struct{})
+	outputReady := make(chan struct{})
+	outputLines := make(chan string)
+	outputDone := make(chan struct{})
+	go streamToChannel(outputReady, stop, outputDone,
+		ptyReader{file: terminal}, outputLines, options.maxLineSize)
+
+	stderrLines := make(chan string)
+	close(stderrLines)
+
+	process = &PTYProcess{
+		Process: &Process{
+			cmd:    adapter,
+			stdout: outputLines,
+			stderr: stderrLines,
+			done:   make(chan struct{}),
+		},
+		terminal: terminal,
+	}
+
+	stopTimeoutWatch := watchTimeout(adapter, options.timeout)
+
+	go func() {
+		<-outputDone
+		close(outputLines)
+		process.waitErr = adapter.Wait()
+		process.timedOut = stopTimeoutWatch()
+		if process.timedOut {
+			process.waitErr = wrapTimeoutError(process.waitErr, options.timeout)
+		}
+		close(stop)
+		_ = terminal.Close()
+		close(process.done)
+	}()
+
+	return process, nil
+}
+
+// Write writes input data to the terminal of the process.
+func (p *PTYProcess) Write(data []byte) (n int, err error) {
+	return p.terminal.Write(data)
+}
+
+// Resize sets the window size of the terminal of the process.
+func (p *PTYProcess) Resize(size WindowSize) error {
+	return setWindowSize(p.terminal, size)
+}
@@ -0,0 +1,71 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func openPTY() (terminal, tty *os.File, err error) {
+	fd, err := unix.Open("/dev/ptmx", unix.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening /dev/ptmx: %w", err)
+	}
+	terminal = os.NewFile(uintptr(fd), "/dev/ptmx")
+
+	err = unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0)
+	if err != nil {
+		_ = terminal.Close()
+		return nil, nil, fmt.Errorf("unlocking pseudo-terminal: %w", err)
+	}
+
+	ptyNumber, err := unix.IoctlGetUint32(fd, unix.TIOCGPTN)
+	if err != nil {
+		_ = terminal.Close()
+		return nil, nil, fmt.Errorf("getting pseudo-terminal number: %w", err)
+	}
+
+	ttyPath := fmt.Sprintf("/dev/pts/%d", ptyNumber)
+	tty, err = os.OpenFile(ttyPath, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		_ = terminal.Close()
+		return nil, nil, fmt.Errorf("opening terminal: %w", err)
+	}
+
+	return terminal, tty, nil
+}
+
+func setWindowSize(terminal *os.File, size WindowSize) error {
+	return unix.IoctlSetWinsize(int(terminal.Fd()), unix.TIOCSWINSZ, &unix.Winsize{
+		Row: size.Rows,
+		Col: size.Columns,
+	})
+}
+
+func ptySysProcAttr(attributes *syscall.SysProcAttr) *syscall.SysProcAttr {
+	if attributes == nil {
+		attributes = &syscall.SysProcAttr{}
+	}
+	attributes.Setsid = true
+	attributes.Setctty = true
+	attributes.Ctty = 0 // stdin file descriptor in the child
+	return attributes
+}
+
+// ptyReader converts the EIO error returned by Linux when reading
+// a pseudo-terminal with no more process attached to it into io.EOF.
+type ptyReader struct {
+	file *os.File
+}
+
+func (r ptyReader) Read(p []byte) (n int, err error) {
+	n, err = r.file.Read(p)
+	if errors.Is(err, syscall.EIO) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,37 @@
+package command
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Cmder_StartPTY(t *testing.T) {
+	t.Parallel()
+
+	cmd := exec.Command("sh", "-c",
+		`test -t 1 && echo terminal; stty size; read line; stty size; echo "got $line"`)
+	process, err := New().StartPTY(cmd, WindowSize{Rows: 24, Columns: 80})
+	require.NoError(t, err)
+
+	_, ok := <-process.Stderr()
+	assert.False(t, ok)
+
+	var lines []string
+	for line := range process.Stdout() {
+		lines = append(lines, line)
+		if line == "24 80" {
+			err = process.Resize(WindowSize{Rows: 40, Columns: 120})
+			require.NoError(t, err)
+			_, err = process.Write([]byte("hello\n"))
+			require.NoError(t, err)
+		}
+	}
+
+	require.NoError(t, process.Wait())
+	assert.Equal(t, 0, process.ExitCode())
+	expectedLines := []string{"terminal", "24 80", "hello", "40 120", "got hello"}
+	assert.Equal(t, expectedLines, lines)
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+
+package command
+
+import (
+	"os"
+	"syscall"
+)
+
+func openPTY() (terminal, tty *os.File, err error) {
+	return nil, nil, ErrPTYNotSupported
+}
+
+func setWindowSize(*os.File, WindowSize) error {
+	return ErrPTYNotSupported
+}
+
+func ptySysProcAttr(attributes *syscall.SysProcAttr) *syscall.SysProcAttr {
+	return attributes
+}
+
+type ptyReader struct {
+	file *os.File
+}
+
+func (r ptyReader) Read(p []byte) (n int, err error) {
+	return r.file.Read(p)
+}
@@ -0,0 +1,95 @@
+package command
+
+import (
+	"io"
+	"os/exec"
+)
+
+// RawProcess is a handle on a started command streaming its
+// stdout and stderr outputs as raw byte chunks, which is safe
+// for binary output.
+type RawProcess struct {
+	*Process
+	stdout <-chan []byte
+	stderr <-chan []byte
+}
+
+// StartRaw launches a command and returns a process handle streaming
+// its stdout and stderr outputs as byte chunks to channels, without
+// any line splitting. Chunks are not reused and can be retained.
+// The stdout and stderr channels are closed once their respective
+// stream is fully drained, and must be read until closed for
+// the process to be marked as done.
+// The MaxOutput and MaxLineSize options are ignored.
+// The options given modify the command, as described on Cmder.
+func (c *Cmder) StartRaw(cmd *exec.Cmd, setters ...OptionSetter) (
+	process *RawProcess, err error) {
+	options := c.newOptions(setters)
+	adapter, err := newCmdAdapter(cmd, options)
+	if err != nil {
+		return nil, err
+	}
+	return startRaw(adapter, options)
+}
+
+func startRaw(cmd processCmd, options options) (process *RawProcess, err error) {
+	stdoutChunks := make(chan []byte)
+	stderrChunks := make(chan []byte)
+	process = &RawProcess{
+		Process: &Process{
+			cmd:  cmd,
+			done: make(chan struct{}),
+		},
+		stdout: stdoutChunks,
+		stderr: stderrChunks,
+	}
+	err = process.start(options,
+		outputStream{
+			stream: func(ready chan<- struct{}, _ <-chan struct{}, done chan<- struct{}, stream io.Reader) {
+				streamChunksToChannel(ready, done, stream, stdoutChunks)
+			},
+			close: func() { close(stdoutChunks) },
+		},
+		outputStream{
+			stream: func(ready chan<- struct{}, _ <-chan struct{}, done chan<- struct{}, stream io.Reader) {
+				streamChunksToChannel(ready, done, stream, stderrChunks)
+			},
+			close: func() { close(stderrChunks) },
+		})
+	if err != nil {
+		return nil, err
+	}
+	return process, nil
+}
+
+// Stdout returns the channel of stdout byte chunks, which is
+// closed once the stdout stream is drained.
+func (p *RawProcess) Stdout() <-chan []byte {
+	return p.stdout
+}
+
+// Stderr returns the channel of stderr byte chunks, which is
+// closed once the stderr stream is drained.
+func (p *RawProcess) Stderr() <-chan []byte {
+	return p.stderr
+}
+
+const rawChunkSize = 32 * 1024 // 32KB
+
+func streamChunksToChannel(ready chan<- struct{}, done chan<- struct{},
+	stream io.Reader, chunks chan<- []byte) {
+	defer close(done)
+	close(ready)
+	for {
+		buffer := make([]byte, rawChunkSize)
+		n, err := stream.Read(buffer)
+		if n > 0 {
+			chunks <- buffer[:n]
+		}
+		if err != nil {
+			// io.EOF, or the stream is closed because the
+			// command failed starting or was killed.
+			return
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"bytes"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Cmder_StartRaw(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	// Binary output with NUL bytes, no trailing new line and
+	// longer than the maximum line size given.
+	cmd := exec.Command("sh", "-c", `printf 'a\000b\nc%.0s' $(seq 1 100); printf 'err\000' >&2`)
+	stdoutTee := bytes.NewBuffer(nil)
+
+	process, err := New().StartRaw(cmd, MaxLineSize(10), TeeOutput(stdoutTee, nil))
+	require.NoError(t, err)
+
+	var stdout, stderr []byte
+	stdoutChunks, stderrChunks := process.Stdout(), process.Stderr()
+	for stdoutChunks != nil || stderrChunks != nil {
+		select {
+		case chunk, ok := <-stdoutChunks:
+			if !ok {
+				stdoutChunks = nil
+				continue
+			}
+			stdout = append(stdout, chunk...)
+		case chunk, ok := <-stderrChunks:
+			if !ok {
+				stderrChunks = nil
+				continue
+			}
+			stderr = append(stderr, chunk...)
+		}
+	}
+
+	require.NoError(t, process.Wait())
+	expectedStdout := bytes.Repeat([]byte("a\x00b\nc"), 100)
+	assert.Equal(t, expectedStdout, stdout)
+	assert.Equal(t, expectedStdout, stdoutTee.Bytes())
+	assert.Equal(t, []byte("err\x00"), stderr)
+}
@@ -0,0 +1,169 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var ErrExitedBeforeReady = errors.New("process exited before being ready")
+
+// StartReady launches a command like StartProcess, and blocks until a
+// line of its stdout or stderr matches the ready regular expression given.
+// All the lines, including the ones received before readiness, are
+// streamed to the channels of the process returned.
+// If the process exits before being ready, an error wrapping
+// ErrExitedBeforeReady is returned. If the context is canceled before
+// the process is ready, the process is killed and an error wrapping
+// the context error is returned. In both cases, the error contains the
+// last output lines and the process returned is nil.
+func (c *Cmder) StartReady(ctx context.Context, cmd *exec.Cmd, ready *regexp.Regexp,
+	setters ...OptionSetter) (process *Process, err error) {
+	realProcess, err := c.StartProcess(cmd, setters...)
+	if err != nil {
+		return nil, err
+	}
+	return waitReady(ctx, realProcess, ready)
+}
+
+func waitReady(ctx context.Context, realProcess *Process, ready *regexp.Regexp) (
+	process *Process, err error) {
+	stdout := make(chan string)
+	stderr := make(chan string)
+	process = &Process{
+		cmd:    realProcess.cmd,
+		stdout: stdout,
+		stderr: stderr,
+		done:   make(chan struct{}),
+	}
+
+	watcher := &readyWatcher{
+		regex:   ready,
+		ready:   make(chan struct{}),
+		decided: make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	const streams = 2
+	wg.Add(streams)
+	go func() {
+		defer wg.Done()
+		watcher.pump(realProcess.Stdout(), stdout)
+	}()
+	go func() {
+		defer wg.Done()
+		watcher.pump(realProcess.Stderr(), stderr)
+	}()
+
+	go func() {
+		wg.Wait()
+		process.waitErr = realProcess.Wait()
+		process.timedOut = realProcess.TimedOut()
+		close(process.done)
+	}()
+
+	select {
+	case <-watcher.ready:
+	case <-realProcess.Done():
+		select {
+		case <-watcher.ready:
+		default:
+			err = fmt.Errorf("%w: %w", ErrExitedBeforeReady, realProcess.Wait())
+		}
+	case <-ctx.Done():
+		_ = realProcess.Signal(os.Kill)
+		err = fmt.Errorf("waiting for readiness: %w", ctx.Err())
+	}
+
+	if err == nil {
+		close(watcher.decided)
+		return process, nil
+	}
+
+	watcher.discard = true
+	close(watcher.decided)
+	<-process.done
+	if lines := watcher.lastLines(); len(lines) > 0 {
+		err = fmt.Errorf("%w; last output lines:\n%s", err, strings.Join(lines, "\n"))
+	}
+	return nil, err
+}
+
+type readyWatcher struct {
+	regex     *regexp.Regexp
+	ready     chan struct{}
+	readyOnce sync.Once
+	// decided is closed once the readiness outcome is decided.
+	decided chan struct{}
+	// discard is set before decided is closed, if the lines
+	// should be discarded instead of being forwarded.
+	discard bool
+
+	linesMutex sync.Mutex
+	lines      []string
+}
+
+// pump buffers the lines received and checks them for readiness until
+// the readiness is decided, and then forwards the buffered lines and
+// the following lines to the output channel, or discards them.
+func (w *readyWatcher) pump(input <-chan string, output chan<- string) {
+	defer close(output)
+
+	var buffered []string
+	inputClosed := false
+bufferLoop:
+	for {
+		select {
+		case line, ok := <-input:
+			if !ok {
+				inputClosed = true
+				<-w.decided
+				break bufferLoop
+			}
+			buffered = append(buffered, line)
+			w.recordLine(line)
+			if w.regex.MatchString(line) {
+				w.readyOnce.Do(func() { close(w.ready) })
+			}
+		case <-w.decided:
+			break bufferLoop
+		}
+	}
+
+	if w.discard {
+		for line := range input {
+			_ = line // drain the input until it is closed
+		}
+		return
+	}
+
+	for _, line := range buffered {
+		output <- line
+	}
+	if !inputClosed {
+		for line := range input {
+			output <- line
+		}
+	}
+}
+
+func (w *readyWatcher) recordLine(line string) {
+	const maxLines = 10
+	w.linesMutex.Lock()
+	defer w.linesMutex.Unlock()
+	w.lines = append(w.lines, line)
+	if len(w.lines) > maxLines {
+		w.lines = w.lines[len(w.lines)-maxLines:]
+	}
+}
+
+func (w *readyWatcher) lastLines() (lines []string) {
+	w.linesMutex.Lock()
+	defer w.linesMutex.Unlock()
+	return append([]string(nil), w.lines...)
+}
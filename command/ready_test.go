@@ -0,0 +1,78 @@
+package command
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Cmder_StartReady(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	readyRegex := regexp.MustCompile(`Initialization Sequence Completed$`)
+
+	t.Run("ready", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := exec.Command("sh", "-c", `echo starting; echo warning >&2; `+
+			`echo "$(date) Initialization Sequence Completed" >&2; read line; echo "$line"`)
+		stdin, err := cmd.StdinPipe()
+		require.NoError(t, err)
+
+		process, err := New().StartReady(context.Background(), cmd, readyRegex)
+		require.NoError(t, err)
+
+		// The command is only sent input once it is ready,
+		// so it cannot exit before being ready.
+		_, err = stdin.Write([]byte("after ready\n"))
+		require.NoError(t, err)
+
+		stdoutLines, stderrLines := readAllLines(t, process.Stdout(), process.Stderr())
+
+		require.NoError(t, process.Wait())
+		assert.Equal(t, []string{"starting", "after ready"}, stdoutLines)
+		require.Len(t, stderrLines, 2)
+		assert.Equal(t, "warning", stderrLines[0])
+		assert.Regexp(t, readyRegex, stderrLines[1])
+	})
+
+	t.Run("exited before ready", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := exec.Command("sh", "-c", `echo starting; echo fatal error >&2; exit 1`)
+
+		process, err := New().StartReady(context.Background(), cmd, readyRegex)
+
+		assert.Nil(t, process)
+		require.ErrorIs(t, err, ErrExitedBeforeReady)
+		var exitErr *exec.ExitError
+		assert.ErrorAs(t, err, &exitErr)
+		assert.Contains(t, err.Error(), "last output lines:\n")
+		assert.Contains(t, err.Error(), "fatal error")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		cmd := exec.Command("sh", "-c", `echo starting; exec sleep 10`)
+
+		process, err := New().StartReady(ctx, cmd, readyRegex)
+
+		assert.Nil(t, process)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, "waiting for readiness: context deadline exceeded; "+
+			"last output lines:\nstarting", err.Error())
+	})
+}
@@ -0,0 +1,173 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sync"
+)
+
+// Recorder runs commands with a *Cmder and records each execution,
+// such that the recorded commands can be saved to a JSON fixture file
+// and replayed deterministically with a Fake cmder.
+type Recorder struct {
+	cmder    *Cmder
+	mutex    sync.Mutex
+	commands []*FakeCommand
+}
+
+// NewRecorder creates a recorder running commands with the cmder given.
+func NewRecorder(cmder *Cmder) *Recorder {
+	return &Recorder{
+		cmder: cmder,
+	}
+}
+
+// Run runs and records the command given, see Cmder.Run.
+func (r *Recorder) Run(cmd *exec.Cmd) (output string, err error) {
+	index := r.reserve(cmd)
+	output, err = r.cmder.Run(cmd)
+	r.record(index, outputToLines(output), nil, exitCode(cmd.ProcessState, err))
+	return output, err
+}
+
+// RunWithOptions runs and records the command given,
+// see Cmder.RunWithOptions.
+func (r *Recorder) RunWithOptions(cmd *exec.Cmd, setters ...OptionSetter) (
+	result Result, err error) {
+	index := r.reserve(cmd)
+	result, err = r.cmder.RunWithOptions(cmd, setters...)
+	r.record(index, outputToLines(result.Output), nil, result.ExitCode)
+	return result, err
+}
+
+// Start starts and records the command given, see Cmder.Start.
+func (r *Recorder) Start(cmd *exec.Cmd) (stdoutLines, stderrLines <-chan string,
+	waitError <-chan error, startErr error) {
+	process, err := r.StartProcess(cmd)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stdoutLines, stderrLines, waitError = forwardProcess(process)
+	return stdoutLines, stderrLines, waitError, nil
+}
+
+// StartProcess starts and records the command given,
+// see Cmder.StartProcess.
+func (r *Recorder) StartProcess(cmd *exec.Cmd, setters ...OptionSetter) (
+	process *Process, err error) {
+	index := r.reserve(cmd)
+	realProcess, err := r.cmder.StartProcess(cmd, setters...)
+	if err != nil {
+		r.discard(index)
+		return nil, err
+	}
+
+	stdoutProxy := make(chan string)
+	stderrProxy := make(chan string)
+	process = &Process{
+		cmd:    realProcess.cmd,
+		stdout: stdoutProxy,
+		stderr: stderrProxy,
+		done:   make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	forward := func(input <-chan string, output chan<- string, recorded *[]string) {
+		defer wg.Done()
+		for line := range input {
+			*recorded = append(*recorded, line)
+			output <- line
+		}
+		close(output)
+	}
+	var recordedStdout, recordedStderr []string
+	const streams = 2
+	wg.Add(streams)
+	go forward(realProcess.Stdout(), stdoutProxy, &recordedStdout)
+	go forward(realProcess.Stderr(), stderrProxy, &recordedStderr)
+
+	go func() {
+		wg.Wait()
+		process.waitErr = realProcess.Wait()
+		process.timedOut = realProcess.TimedOut()
+		r.record(index, recordedStdout, recordedStderr, realProcess.ExitCode())
+		close(process.done)
+	}()
+
+	return process, nil
+}
+
+// Commands returns the commands recorded so far, in the order
+// they were started.
+func (r *Recorder) Commands() (commands []FakeCommand) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	commands = make([]FakeCommand, 0, len(r.commands))
+	for _, command := range r.commands {
+		if command != nil {
+			commands = append(commands, *command)
+		}
+	}
+	return commands
+}
+
+// Save writes the commands recorded so far to a JSON fixture file
+// at the path given, which can be loaded with LoadFake.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Commands(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding commands: %w", err)
+	}
+
+	const permissions os.FileMode = 0600
+	err = os.WriteFile(path, data, permissions)
+	if err != nil {
+		return fmt.Errorf("writing fixture file: %w", err)
+	}
+	return nil
+}
+
+// reserve reserves a slot for the command given, to keep the
+// recorded commands in the order they were started.
+func (r *Recorder) reserve(cmd *exec.Cmd) (index int) {
+	command := &FakeCommand{
+		Path:  regexp.QuoteMeta(cmd.Path),
+		Times: 1,
+	}
+	if len(cmd.Args) > 1 {
+		command.Args = make([]string, len(cmd.Args)-1)
+		for i, arg := range cmd.Args[1:] {
+			command.Args[i] = regexp.QuoteMeta(arg)
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.commands = append(r.commands, command)
+	return len(r.commands) - 1
+}
+
+func (r *Recorder) record(index int, stdout, stderr []string, exitCode int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	command := r.commands[index]
+	command.Stdout = stdout
+	command.Stderr = stderr
+	command.ExitCode = exitCode
+}
+
+func (r *Recorder) discard(index int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.commands[index] = nil
+}
+
+func outputToLines(output string) (lines []string) {
+	if output == "" {
+		return nil
+	}
+	return stringToLines(output)
+}
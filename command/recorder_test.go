@@ -0,0 +1,89 @@
+package command
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Recorder(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	const script = "echo hello; echo warning >&2; exit 3"
+	recorder := NewRecorder(New())
+
+	recordedOutput, recordedRunErr := recorder.Run(exec.Command("sh", "-c", "echo 'a.b'"))
+	require.NoError(t, recordedRunErr)
+
+	process, err := recorder.StartProcess(exec.Command("sh", "-c", script))
+	require.NoError(t, err)
+	recordedStdout, recordedStderr := readAllLines(t, process.Stdout(), process.Stderr())
+	require.Error(t, process.Wait())
+	assert.Equal(t, 3, process.ExitCode())
+
+	fixturePath := filepath.Join(t.TempDir(), "fixture.json")
+	err = recorder.Save(fixturePath)
+	require.NoError(t, err)
+
+	fake, err := LoadFake(fixturePath)
+	require.NoError(t, err)
+
+	output, err := fake.Run(exec.Command("sh", "-c", "echo 'a.b'"))
+	require.NoError(t, err)
+	assert.Equal(t, recordedOutput, output)
+
+	// The recorded command path is escaped and does not match other paths.
+	_, err = fake.Run(exec.Command("sh", "-c", "echo 'aXb'"))
+	assert.ErrorIs(t, err, ErrFakeNoMatch)
+
+	process, err = fake.StartProcess(exec.Command("sh", "-c", script))
+	require.NoError(t, err)
+	stdout, stderr := readAllLines(t, process.Stdout(), process.Stderr())
+	require.Error(t, process.Wait())
+	assert.Equal(t, 3, process.ExitCode())
+	assert.Equal(t, recordedStdout, stdout)
+	assert.Equal(t, recordedStderr, stderr)
+
+	// Recorded commands can only be replayed once.
+	_, err = fake.StartProcess(exec.Command("sh", "-c", script))
+	assert.ErrorIs(t, err, ErrFakeNoMatch)
+}
+
+func Test_Recorder_sequentialStreams(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	const script = "echo hello; echo warning >&2; echo world"
+	recorder := NewRecorder(New())
+
+	process, err := recorder.StartProcess(exec.Command("sh", "-c", script))
+	require.NoError(t, err)
+
+	// Read stdout until it closes before reading stderr.
+	var stdout, stderr []string
+	for line := range process.Stdout() {
+		stdout = append(stdout, line)
+	}
+	for line := range process.Stderr() {
+		stderr = append(stderr, line)
+	}
+	require.NoError(t, process.Wait())
+
+	assert.Equal(t, []string{"hello", "world"}, stdout)
+	assert.Equal(t, []string{"warning"}, stderr)
+	commands := recorder.Commands()
+	require.Len(t, commands, 1)
+	assert.Equal(t, stdout, commands[0].Stdout)
+	assert.Equal(t, stderr, commands[0].Stderr)
+}
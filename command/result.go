@@ -0,0 +1,107 @@
+package command
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Result contains information on a command run.
+type Result struct {
+	// Output is the combined stdout and stderr output of the command.
+	// It may be truncated if the MaxOutput option is set.
+	Output string
+	// ExitCode is the exit code of the command, or -1 if the command
+	// did not exit normally, for example if it was killed.
+	ExitCode int
+	// Duration is the time elapsed between the command start
+	// and its end.
+	Duration time.Duration
+	// TruncatedBytes is the number of output bytes discarded
+	// because of the MaxOutput option.
+	TruncatedBytes int64
+	// TimedOut is true if the command was killed because of
+	// the Timeout option.
+	TimedOut bool
+	// Usage is the resource usage of the command.
+	Usage Usage
+}
+
+// Truncated returns true if some output was discarded.
+func (r Result) Truncated() bool {
+	return r.TruncatedBytes > 0
+}
+
+// RunWithOptions runs a command in a blocking manner with the
+// options given, returning a result and an error if it failed.
+// If the command timed out, the error wraps ErrTimedOut.
+// The MaxLineSize and TeeOutput options are ignored.
+// The options given modify the command, as described on Cmder.
+func (c *Cmder) RunWithOptions(cmd *exec.Cmd, setters ...OptionSetter) (
+	result Result, err error) {
+	options := c.newOptions(setters)
+	if options.timeout > 0 && cmd.WaitDelay == 0 {
+		// Do not hang after the timeout if a child process
+		// inherited the output pipe and keeps it open.
+		const defaultWaitDelay = time.Second
+		cmd.WaitDelay = defaultWaitDelay
+	}
+	adapter, err := newCmdAdapter(cmd, options)
+	if err != nil {
+		return Result{ExitCode: -1}, err
+	}
+	return runWithOptions(adapter, options)
+}
+
+func runWithOptions(cmd processCmd, options options) (result Result, err error) {
+	buffer := newCappedBuffer(options.outputHead, options.outputTail)
+	cmd.SetOutput(buffer)
+
+	startTime := time.Now()
+	err = cmd.Start()
+	if err != nil {
+		return Result{ExitCode: -1}, err
+	}
+
+	err = applyRLimitsOrKill(cmd, options.rlimits)
+	if err != nil {
+		return Result{ExitCode: -1}, err
+	}
+
+	stopTimeoutWatch := watchTimeout(cmd, options.timeout)
+	err = cmd.Wait()
+	result.TimedOut = stopTimeoutWatch()
+	result.Duration = time.Since(startTime)
+
+	result.Output = formatOutput(buffer.Bytes())
+	result.TruncatedBytes = buffer.Truncated()
+	state := cmd.State()
+	result.ExitCode = exitCode(state, err)
+	result.Usage = usageFromState(state)
+
+	if result.TimedOut {
+		err = wrapTimeoutError(err, options.timeout)
+	}
+
+	return result, err
+}
+
+// exitCode returns the exit code from the process state given,
+// or from the wait error if the state is nil. It returns -1 if
+// the process did not exit normally.
+func exitCode(state *os.ProcessState, waitErr error) int {
+	if state != nil {
+		return state.ExitCode()
+	}
+
+	if waitErr == nil {
+		return 0
+	}
+
+	var exitCoder interface{ ExitCode() int }
+	if errors.As(waitErr, &exitCoder) {
+		return exitCoder.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,141 @@
+package command
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_runWithOptions(t *testing.T) {
+	t.Parallel()
+
+	errDummy := errors.New("dummy")
+
+	testCases := map[string]struct {
+		options  options
+		written  string
+		startErr error
+		waitErr  error
+		result   Result
+		err      error
+	}{
+		"start error": {
+			startErr: errDummy,
+			result:   Result{ExitCode: -1},
+			err:      errDummy,
+		},
+		"wait error": {
+			written: "'hello'\nworld\n",
+			waitErr: errDummy,
+			result: Result{
+				Output:   "hello\nworld",
+				ExitCode: -1,
+			},
+			err: errDummy,
+		},
+		"truncated output": {
+			options: options{outputHead: 3, outputTail: 3},
+			written: "abc123456def",
+			result: Result{
+				Output:         "abc\n[... 6 bytes truncated ...]\ndef",
+				ExitCode:       0,
+				TruncatedBytes: 6,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			mockCmd := NewMockprocessCmd(ctrl)
+			var writer io.Writer
+			mockCmd.EXPECT().SetOutput(gomock.Any()).
+				Do(func(w io.Writer) { writer = w })
+			mockCmd.EXPECT().Start().Return(testCase.startErr)
+			if testCase.startErr == nil {
+				mockCmd.EXPECT().Wait().DoAndReturn(func() error {
+					_, err := writer.Write([]byte(testCase.written))
+					require.NoError(t, err)
+					return testCase.waitErr
+				})
+				mockCmd.EXPECT().State().Return(nil)
+			}
+
+			result, err := runWithOptions(mockCmd, testCase.options)
+
+			if testCase.err != nil {
+				require.Error(t, err)
+				assert.Equal(t, testCase.err.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			result.Duration = 0
+			assert.Equal(t, testCase.result, result)
+		})
+	}
+}
+
+func Test_Cmder_RunWithOptions(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	t.Run("exit code", func(t *testing.T) {
+		t.Parallel()
+
+		cmder := New()
+		cmd := exec.Command("sh", "-c", "echo hello; exit 2")
+
+		result, err := cmder.RunWithOptions(cmd)
+
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, "hello", result.Output)
+		assert.Equal(t, 2, result.ExitCode)
+		assert.False(t, result.TimedOut)
+		assert.False(t, result.Truncated())
+	})
+
+	t.Run("negative max output", func(t *testing.T) {
+		t.Parallel()
+
+		cmder := New()
+		cmd := exec.Command("echo", "hello")
+
+		result, err := cmder.RunWithOptions(cmd, MaxOutput(-1, -1))
+
+		require.NoError(t, err)
+		assert.Equal(t, "hello", result.Output)
+		assert.False(t, result.Truncated())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		cmder := New()
+		cmd := exec.Command("sh", "-c", "echo hello; exec sleep 10")
+
+		const timeout = 100 * time.Millisecond
+		result, err := cmder.RunWithOptions(cmd, Timeout(timeout))
+
+		require.ErrorIs(t, err, ErrTimedOut)
+		assert.Equal(t, "hello", result.Output)
+		assert.Equal(t, -1, result.ExitCode)
+		assert.True(t, result.TimedOut)
+		assert.Less(t, result.Duration, 5*time.Second)
+	})
+}
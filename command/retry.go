@@ -0,0 +1,246 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Runner runs a command with options and returns its result.
+// It is implemented by *Cmder, *Fake and *Recorder.
+type Runner interface {
+	RunWithOptions(cmd *exec.Cmd, setters ...OptionSetter) (Result, error)
+}
+
+// Classifier returns true if a failed command run can be retried.
+type Classifier func(result Result, err error) (retryable bool)
+
+// NewClassifier returns a classifier considering a failed run retryable
+// if it exited with one of the exit codes given, or if its output
+// matches one of the regular expressions given. Note the output is the
+// combined stdout and stderr output of the command.
+func NewClassifier(exitCodes []int, outputRegexes ...*regexp.Regexp) Classifier {
+	retryableCodes := make(map[int]struct{}, len(exitCodes))
+	for _, exitCode := range exitCodes {
+		retryableCodes[exitCode] = struct{}{}
+	}
+
+	return func(result Result, _ error) (retryable bool) {
+		if _, ok := retryableCodes[result.ExitCode]; ok {
+			return true
+		}
+		for _, regex := range outputRegexes {
+			if regex.MatchString(result.Output) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetrySettings are the settings for a Retrier.
+type RetrySettings struct {
+	// Classifier decides if a failed run can be retried.
+	// It defaults to retrying any failed run.
+	Classifier Classifier
+	// MaxAttempts is the maximum number of runs, and defaults to 3.
+	MaxAttempts int
+	// InitialBackoff is the duration to wait for before the second
+	// run, and defaults to 1 second.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum duration to wait for between
+	// two runs, and defaults to 30 seconds.
+	MaxBackoff time.Duration
+	// Multiplier is the factor applied to the backoff duration
+	// after each run, and defaults to 2.
+	Multiplier float64
+	// OutputTail is the maximum number of bytes from the end of the
+	// output of each run to report in errors, and defaults to 256.
+	OutputTail int
+}
+
+func (s *RetrySettings) setDefaults() {
+	if s.Classifier == nil {
+		s.Classifier = func(Result, error) bool { return true }
+	}
+	if s.MaxAttempts == 0 {
+		const defaultMaxAttempts = 3
+		s.MaxAttempts = defaultMaxAttempts
+	}
+	if s.InitialBackoff == 0 {
+		s.InitialBackoff = time.Second
+	}
+	if s.MaxBackoff == 0 {
+		const defaultMaxBackoff = 30 * time.Second
+		s.MaxBackoff = defaultMaxBackoff
+	}
+	if s.Multiplier == 0 {
+		const defaultMultiplier = 2
+		s.Multiplier = defaultMultiplier
+	}
+	if s.OutputTail == 0 {
+		const defaultOutputTail = 256
+		s.OutputTail = defaultOutputTail
+	}
+}
+
+var ErrRetrySettingsInvalid = errors.New("retry settings are not valid")
+
+func (s *RetrySettings) validate() (err error) {
+	switch {
+	case s.MaxAttempts < 0:
+		return fmt.Errorf("%w: max attempts %d cannot be negative",
+			ErrRetrySettingsInvalid, s.MaxAttempts)
+	case s.InitialBackoff < 0:
+		return fmt.Errorf("%w: initial backoff %s cannot be negative",
+			ErrRetrySettingsInvalid, s.InitialBackoff)
+	case s.MaxBackoff < 0:
+		return fmt.Errorf("%w: maximum backoff %s cannot be negative",
+			ErrRetrySettingsInvalid, s.MaxBackoff)
+	case s.Multiplier < 0:
+		return fmt.Errorf("%w: multiplier %g cannot be negative",
+			ErrRetrySettingsInvalid, s.Multiplier)
+	case s.OutputTail < 0:
+		return fmt.Errorf("%w: output tail %d cannot be negative",
+			ErrRetrySettingsInvalid, s.OutputTail)
+	}
+	return nil
+}
+
+// Retrier runs commands and retries them on retryable failures
+// with an exponential backoff.
+type Retrier struct {
+	runner   Runner
+	settings RetrySettings
+}
+
+// NewRetrier creates a retrier running commands with the runner given.
+// It returns an error wrapping ErrRetrySettingsInvalid if a setting
+// is negative.
+func NewRetrier(runner Runner, settings RetrySettings) (retrier *Retrier, err error) {
+	settings.setDefaults()
+	err = settings.validate()
+	if err != nil {
+		return nil, err
+	}
+	return &Retrier{
+		runner:   runner,
+		settings: settings,
+	}, nil
+}
+
+var (
+	ErrRetryFatal     = errors.New("command failed with a non retryable error")
+	ErrRetryExhausted = errors.New("command failed on all attempts")
+)
+
+// AttemptError contains information on a failed run attempt.
+type AttemptError struct {
+	// Attempt is the attempt number, starting from 1.
+	Attempt int
+	// ExitCode is the exit code of the command run.
+	ExitCode int
+	// OutputTail is the end of the output of the command run.
+	OutputTail string
+	// Err is the error of the command run.
+	Err error
+}
+
+func (e *AttemptError) Error() string {
+	message := fmt.Sprintf("attempt %d: exit code %d: %s",
+		e.Attempt, e.ExitCode, e.Err)
+	if e.OutputTail != "" {
+		message += fmt.Sprintf(": output: %q", e.OutputTail)
+	}
+	return message
+}
+
+func (e *AttemptError) Unwrap() error {
+	return e.Err
+}
+
+// RetryError is returned by the Retrier when all the attempts failed,
+// a non retryable error occurred or the context was canceled.
+type RetryError struct {
+	// Attempts contains each failed attempt, in order.
+	Attempts []AttemptError
+	// Reason is ErrRetryFatal, ErrRetryExhausted or the context error.
+	Reason error
+}
+
+func (e *RetryError) Error() string {
+	attemptMessages := make([]string, len(e.Attempts))
+	for i := range e.Attempts {
+		attemptMessages[i] = e.Attempts[i].Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Reason, strings.Join(attemptMessages, "; "))
+}
+
+// Unwrap returns the reason error and the error of the last attempt.
+func (e *RetryError) Unwrap() []error {
+	errs := []error{e.Reason}
+	if len(e.Attempts) > 0 {
+		errs = append(errs, &e.Attempts[len(e.Attempts)-1])
+	}
+	return errs
+}
+
+// Run runs the command created by newCmd, and creates and runs a new
+// command each time the run fails with a retryable error, since a
+// command cannot be run more than once. The context given is passed
+// to newCmd and is used to abort waiting between attempts.
+// It returns the result of the last run, and an error of type
+// *RetryError if it did not succeed.
+func (r *Retrier) Run(ctx context.Context, newCmd func(ctx context.Context) *exec.Cmd,
+	setters ...OptionSetter) (result Result, err error) {
+	retryErr := &RetryError{}
+	backoff := r.settings.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		result, err = r.runner.RunWithOptions(newCmd(ctx), setters...)
+		if err == nil {
+			return result, nil
+		}
+
+		retryErr.Attempts = append(retryErr.Attempts, AttemptError{
+			Attempt:    attempt,
+			ExitCode:   result.ExitCode,
+			OutputTail: tail(result.Output, r.settings.OutputTail),
+			Err:        err,
+		})
+
+		switch {
+		case ctx.Err() != nil:
+			retryErr.Reason = ctx.Err()
+			return result, retryErr
+		case !r.settings.Classifier(result, err):
+			retryErr.Reason = ErrRetryFatal
+			return result, retryErr
+		case attempt >= r.settings.MaxAttempts:
+			retryErr.Reason = ErrRetryExhausted
+			return result, retryErr
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			retryErr.Reason = ctx.Err()
+			return result, retryErr
+		case <-timer.C:
+		}
+
+		backoff = time.Duration(float64(backoff) * r.settings.Multiplier)
+		backoff = min(backoff, r.settings.MaxBackoff)
+	}
+}
+
+func tail(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	return s[len(s)-maxBytes:]
+}
@@ -0,0 +1,136 @@
+package command
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewClassifier(t *testing.T) {
+	t.Parallel()
+
+	classifier := NewClassifier([]int{75},
+		regexp.MustCompile(`(?m)^E: Could not get lock`))
+
+	assert.True(t, classifier(Result{ExitCode: 75}, nil))
+	assert.True(t, classifier(Result{
+		ExitCode: 100,
+		Output:   "Reading package lists...\nE: Could not get lock /var/lib/dpkg/lock",
+	}, nil))
+	assert.False(t, classifier(Result{ExitCode: 100, Output: "E: Unable to locate package"}, nil))
+}
+
+func Test_Retrier_Run(t *testing.T) {
+	t.Parallel()
+
+	newCmd := func(ctx context.Context) *exec.Cmd {
+		return exec.CommandContext(ctx, "/usr/bin/apt-get", "install")
+	}
+
+	testCases := map[string]struct {
+		commands []FakeCommand
+		settings RetrySettings
+		result   Result
+		errs     []error
+		errMsg   string
+	}{
+		"success after retries": {
+			commands: []FakeCommand{
+				{Path: ".*", Args: []string{".*"}, Stdout: []string{"locked"}, ExitCode: 75, Times: 2},
+				{Path: ".*", Args: []string{".*"}, Stdout: []string{"done"}},
+			},
+			settings: RetrySettings{MaxAttempts: 3},
+			result:   Result{Output: "done"},
+		},
+		"exhausted": {
+			commands: []FakeCommand{
+				{Path: ".*", Args: []string{".*"}, Stdout: []string{"some long output"}, ExitCode: 75},
+			},
+			settings: RetrySettings{MaxAttempts: 2, OutputTail: 6},
+			result:   Result{Output: "some long output", ExitCode: 75},
+			errs:     []error{ErrRetryExhausted},
+			errMsg: "command failed on all attempts: " +
+				`attempt 1: exit code 75: exit status 75: output: "output"; ` +
+				`attempt 2: exit code 75: exit status 75: output: "output"`,
+		},
+		"fatal": {
+			commands: []FakeCommand{
+				{Path: ".*", Args: []string{".*"}, ExitCode: 75, Times: 1},
+				{Path: ".*", Args: []string{".*"}, ExitCode: 100},
+			},
+			settings: RetrySettings{
+				MaxAttempts: 5,
+				Classifier:  NewClassifier([]int{75}),
+			},
+			result: Result{ExitCode: 100},
+			errs:   []error{ErrRetryFatal},
+			errMsg: "command failed with a non retryable error: " +
+				"attempt 1: exit code 75: exit status 75; " +
+				"attempt 2: exit code 100: exit status 100",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			fake, err := NewFake(testCase.commands)
+			require.NoError(t, err)
+			settings := testCase.settings
+			settings.InitialBackoff = time.Millisecond
+			retrier, err := NewRetrier(fake, settings)
+			require.NoError(t, err)
+
+			result, err := retrier.Run(context.Background(), newCmd)
+
+			for _, expectedErr := range testCase.errs {
+				assert.ErrorIs(t, err, expectedErr)
+			}
+			if testCase.errMsg != "" {
+				require.Error(t, err)
+				assert.Equal(t, testCase.errMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			result.Duration = 0
+			assert.Equal(t, testCase.result, result)
+		})
+	}
+}
+
+func Test_Retrier_Run_canceled(t *testing.T) {
+	t.Parallel()
+
+	fake, err := NewFake([]FakeCommand{{Path: ".*", ExitCode: 1}})
+	require.NoError(t, err)
+	retrier, err := NewRetrier(fake, RetrySettings{InitialBackoff: time.Hour})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = retrier.Run(ctx, func(ctx context.Context) *exec.Cmd {
+		return exec.CommandContext(ctx, "/bin/false")
+	})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	var retryErr *RetryError
+	require.ErrorAs(t, err, &retryErr)
+	assert.Len(t, retryErr.Attempts, 1)
+}
+
+func Test_NewRetrier(t *testing.T) {
+	t.Parallel()
+
+	retrier, err := NewRetrier(nil, RetrySettings{MaxAttempts: -1})
+
+	assert.Nil(t, retrier)
+	assert.ErrorIs(t, err, ErrRetrySettingsInvalid)
+	assert.EqualError(t, err, "retry settings are not valid: "+
+		"max attempts -1 cannot be negative")
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"errors"
+	"os"
+)
+
+var (
+	ErrRLimitsNotSupported = errors.New("resource limits not supported on this platform")
+	ErrRLimitsPIDInvalid   = errors.New("process ID is not valid to set resource limits")
+)
+
+type rlimitResource uint8
+
+const (
+	rlimitAddressSpace rlimitResource = iota
+	rlimitOpenFiles
+	rlimitCPUSeconds
+	rlimitCoreSize
+)
+
+func (r rlimitResource) String() string {
+	switch r {
+	case rlimitAddressSpace:
+		return "address space"
+	case rlimitOpenFiles:
+		return "open files"
+	case rlimitCPUSeconds:
+		return "CPU time"
+	case rlimitCoreSize:
+		return "core size"
+	default:
+		return "unknown"
+	}
+}
+
+type rlimit struct {
+	resource rlimitResource
+	value    uint64
+}
+
+func withRLimit(resource rlimitResource, value uint64) OptionSetter {
+	return func(o *options) {
+		for i := range o.rlimits {
+			if o.rlimits[i].resource == resource {
+				o.rlimits[i].value = value
+				return
+			}
+		}
+		o.rlimits = append(o.rlimits, rlimit{resource: resource, value: value})
+	}
+}
+
+// LimitAddressSpace limits the virtual memory address space
+// of the command to the number of bytes given.
+// Resource limits are only supported on Linux, and are applied right
+// after the command starts, so its very first instructions may
+// run without them.
+func LimitAddressSpace(bytes uint64) OptionSetter {
+	return withRLimit(rlimitAddressSpace, bytes)
+}
+
+// LimitOpenFiles limits the number of file descriptors
+// the command can open. See LimitAddressSpace for the limitations.
+func LimitOpenFiles(files uint64) OptionSetter {
+	return withRLimit(rlimitOpenFiles, files)
+}
+
+// LimitCPUTime limits the CPU time of the command to the number
+// of seconds given. See LimitAddressSpace for the limitations.
+func LimitCPUTime(seconds uint64) OptionSetter {
+	return withRLimit(rlimitCPUSeconds, seconds)
+}
+
+// LimitCoreSize limits the size of core dump files the command can
+// produce to the number of bytes given, and zero disables them.
+// See LimitAddressSpace for the limitations.
+func LimitCoreSize(bytes uint64) OptionSetter {
+	return withRLimit(rlimitCoreSize, bytes)
+}
+
+// applyRLimitsOrKill applies the resource limits to the started
+// command, and kills it and waits for it if this fails.
+func applyRLimitsOrKill(cmd processCmd, rlimits []rlimit) (err error) {
+	if len(rlimits) == 0 {
+		return nil
+	}
+
+	err = setRLimits(cmd.PID(), rlimits)
+	if err != nil {
+		_ = cmd.Signal(os.Kill)
+		_ = cmd.Wait()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package command
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+func setRLimits(pid int, rlimits []rlimit) (err error) {
+	// A zero PID would set the resource limits of the current process.
+	if pid <= 0 {
+		return fmt.Errorf("%w: %d", ErrRLimitsPIDInvalid, pid)
+	}
+
+	for _, limit := range rlimits {
+		var resource int
+		switch limit.resource {
+		case rlimitAddressSpace:
+			resource = unix.RLIMIT_AS
+		case rlimitOpenFiles:
+			resource = unix.RLIMIT_NOFILE
+		case rlimitCPUSeconds:
+			resource = unix.RLIMIT_CPU
+		case rlimitCoreSize:
+			resource = unix.RLIMIT_CORE
+		}
+
+		value := &unix.Rlimit{Cur: limit.value, Max: limit.value}
+		err = unix.Prlimit(pid, resource, value, nil)
+		if err != nil {
+			return fmt.Errorf("setting %s resource limit: %w", limit.resource, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package command
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func Test_Cmder_StartProcess_rlimits(t *testing.T) {
+	t.Parallel()
+
+	cmd := exec.Command("sh", "-c", "read line; ulimit -n; ulimit -c; ulimit -t")
+	stdin, err := cmd.StdinPipe()
+	require.NoError(t, err)
+
+	process, err := New().StartProcess(cmd,
+		LimitOpenFiles(64), LimitCoreSize(0), LimitCPUTime(30))
+	require.NoError(t, err)
+
+	// Resource limits are applied once StartProcess returns.
+	_, err = stdin.Write([]byte("\n"))
+	require.NoError(t, err)
+
+	stdoutLines, stderrLines := readAllLines(t, process.Stdout(), process.Stderr())
+
+	require.NoError(t, process.Wait())
+	assert.Equal(t, []string{"64", "0", "30"}, stdoutLines)
+	assert.Empty(t, stderrLines)
+
+	usage := process.Usage()
+	assert.Positive(t, usage.MaxRSS)
+}
+
+func Test_Cmder_RunWithOptions_usage(t *testing.T) {
+	t.Parallel()
+
+	cmd := exec.Command("sh", "-c", "i=0; while [ $i -lt 10000 ]; do i=$((i+1)); done")
+
+	result, err := New().RunWithOptions(cmd, LimitAddressSpace(1<<30))
+	require.NoError(t, err)
+
+	assert.Positive(t, result.Usage.MaxRSS)
+	assert.Positive(t, result.Usage.UserTime+result.Usage.SystemTime)
+}
+
+func Test_Cmder_Start_rlimits(t *testing.T) {
+	t.Parallel()
+
+	cmd := exec.Command("sh", "-c", "read line; ulimit -n")
+	stdin, err := cmd.StdinPipe()
+	require.NoError(t, err)
+
+	stdoutLines, _, waitError, err := NewWithDefaults(LimitOpenFiles(32)).Start(cmd)
+	require.NoError(t, err)
+
+	_, err = stdin.Write([]byte("\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "32", <-stdoutLines)
+	assert.NoError(t, <-waitError)
+}
+
+func Test_setRLimits_invalidPID(t *testing.T) {
+	t.Parallel()
+
+	err := setRLimits(0, []rlimit{{resource: rlimitOpenFiles, value: 7}})
+
+	assert.ErrorIs(t, err, ErrRLimitsPIDInvalid)
+	assert.EqualError(t, err, "process ID is not valid to set resource limits: 0")
+}
+
+func Test_Fake_rlimits(t *testing.T) {
+	t.Parallel()
+
+	var before unix.Rlimit
+	err := unix.Getrlimit(unix.RLIMIT_NOFILE, &before)
+	require.NoError(t, err)
+
+	fake, err := NewFake([]FakeCommand{{Path: "tool", Stdout: []string{"ok"}}})
+	require.NoError(t, err)
+
+	_, err = fake.RunWithOptions(exec.Command("tool"), LimitOpenFiles(7))
+	require.NoError(t, err)
+
+	process, err := fake.StartProcess(exec.Command("tool"), LimitOpenFiles(8), LimitCoreSize(0))
+	require.NoError(t, err)
+	readAllLines(t, process.Stdout(), process.Stderr())
+	require.NoError(t, process.Wait())
+
+	_, err = fake.RunWithOptions(exec.Command("tool"))
+	require.NoError(t, err)
+
+	var after unix.Rlimit
+	err = unix.Getrlimit(unix.RLIMIT_NOFILE, &after)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	expected := []map[string]uint64{
+		{"open files": 7},
+		{"open files": 8, "core size": 0},
+		nil,
+	}
+	assert.Equal(t, expected, fake.RLimits())
+}
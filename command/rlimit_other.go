@@ -0,0 +1,7 @@
+//go:build !linux
+
+package command
+
+func setRLimits(int, []rlimit) error {
+	return ErrRLimitsNotSupported
+}
@@ -6,13 +6,19 @@ import (
 )
 
 // Run runs a command in a blocking manner, returning its output and
-// an error if it failed.
+// an error if it failed. The default options of the cmder are applied,
+// and modify the command, as with RunWithOptions.
 func (c *Cmder) Run(cmd *exec.Cmd) (output string, err error) {
-	return run(cmd)
+	result, err := c.RunWithOptions(cmd)
+	return result.Output, err
 }
 
 func run(cmd execCmd) (output string, err error) {
 	stdout, err := cmd.CombinedOutput()
+	return formatOutput(stdout), err
+}
+
+func formatOutput(stdout []byte) (output string) {
 	output = string(stdout)
 	output = strings.TrimSuffix(output, "\n")
 	lines := stringToLines(output)
@@ -20,8 +26,7 @@ func run(cmd execCmd) (output string, err error) {
 		lines[i] = strings.TrimPrefix(lines[i], "'")
 		lines[i] = strings.TrimSuffix(lines[i], "'")
 	}
-	output = strings.Join(lines, "\n")
-	return output, err
+	return strings.Join(lines, "\n")
 }
 
 func stringToLines(s string) (lines []string) {
@@ -0,0 +1,219 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Clock gives the current time and waits for durations.
+// It can be injected in a Scheduler to control time in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// OverlapPolicy defines what to do when a job is scheduled
+// to run while its previous run is still running.
+type OverlapPolicy uint8
+
+const (
+	// OverlapSkip skips the run, which is reported with an
+	// error wrapping ErrScheduledRunSkipped.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue runs the job once its previous runs finished.
+	OverlapQueue
+	// OverlapAllow runs the job concurrently with its previous runs.
+	OverlapAllow
+)
+
+// ScheduledJob is a command to run on a cron schedule.
+type ScheduledJob struct {
+	// Name identifies the job in the runs reported.
+	Name string
+	// Schedule is the cron expression to parse with ParseSchedule.
+	Schedule string
+	// NewCmd creates the command for each run, since a command cannot
+	// be run more than once. The context given is canceled when the
+	// scheduler stops, and should be used with exec.CommandContext.
+	NewCmd func(ctx context.Context) *exec.Cmd
+	// Overlap is the policy for runs overlapping with previous runs,
+	// and defaults to OverlapSkip.
+	Overlap OverlapPolicy
+	// Options are the option setters for each run of the job.
+	Options []OptionSetter
+}
+
+// ScheduledRun is the report of a scheduled job run.
+type ScheduledRun struct {
+	// Job is the name of the job.
+	Job string
+	// ScheduledTime is the time the run was scheduled at.
+	ScheduledTime time.Time
+	// Result is the result of the run.
+	Result Result
+	// Err is the error of the run, if any.
+	Err error
+}
+
+// SchedulerSettings are the settings for a Scheduler.
+type SchedulerSettings struct {
+	// Clock is the clock to use, and defaults to the system clock.
+	Clock Clock
+	// OnRun is called after each run finished or was skipped,
+	// and defaults to a no-op function. It can be called
+	// concurrently from different goroutines.
+	OnRun func(run ScheduledRun)
+}
+
+func (s *SchedulerSettings) setDefaults() {
+	if s.Clock == nil {
+		s.Clock = realClock{}
+	}
+	if s.OnRun == nil {
+		s.OnRun = func(ScheduledRun) {}
+	}
+}
+
+// Scheduler runs commands on cron schedules.
+type Scheduler struct {
+	runner   Runner
+	settings SchedulerSettings
+	jobs     []*scheduledJob
+}
+
+type scheduledJob struct {
+	ScheduledJob
+	schedule *Schedule
+	mutex    sync.Mutex
+	running  int
+	queued   []time.Time
+}
+
+// NewScheduler creates a scheduler running commands with the runner given.
+func NewScheduler(runner Runner, settings SchedulerSettings) *Scheduler {
+	settings.setDefaults()
+	return &Scheduler{
+		runner:   runner,
+		settings: settings,
+	}
+}
+
+var ErrScheduledRunSkipped = errors.New("scheduled run skipped")
+
+// Add adds a job to the scheduler. It returns an error if the job
+// schedule cannot be parsed. It must be called before Run.
+func (s *Scheduler) Add(job ScheduledJob) error {
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("parsing schedule of job %s: %w", job.Name, err)
+	}
+	s.jobs = append(s.jobs, &scheduledJob{
+		ScheduledJob: job,
+		schedule:     schedule,
+	})
+	return nil
+}
+
+// Run runs the jobs on their schedules until the context is canceled.
+// Once canceled, queued runs are dropped and Run waits for the running
+// commands to exit before returning. The commands are given a context
+// canceled when the context given is canceled.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job *scheduledJob) {
+			defer wg.Done()
+			s.runJob(ctx, job, &wg)
+		}(job)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) runJob(ctx context.Context, job *scheduledJob, wg *sync.WaitGroup) {
+	var scheduled time.Time
+	for {
+		now := s.settings.Clock.Now()
+		if scheduled.IsZero() || !scheduled.After(now) {
+			// Skip missed runs if the clock jumped forward,
+			// or schedule the first run.
+			scheduled = job.schedule.Next(now)
+		}
+		if scheduled.IsZero() {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.settings.Clock.After(scheduled.Sub(now)):
+		}
+
+		s.trigger(ctx, job, scheduled, wg)
+		scheduled = job.schedule.Next(scheduled)
+	}
+}
+
+func (s *Scheduler) trigger(ctx context.Context, job *scheduledJob,
+	scheduled time.Time, wg *sync.WaitGroup) {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	if job.running > 0 {
+		switch job.Overlap {
+		case OverlapSkip:
+			s.settings.OnRun(ScheduledRun{
+				Job:           job.Name,
+				ScheduledTime: scheduled,
+				Result:        Result{ExitCode: -1},
+				Err: fmt.Errorf("%w: %d previous run(s) still running",
+					ErrScheduledRunSkipped, job.running),
+			})
+			return
+		case OverlapQueue:
+			job.queued = append(job.queued, scheduled)
+			return
+		case OverlapAllow:
+		}
+	}
+
+	job.running++
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.run(ctx, job, scheduled)
+	}()
+}
+
+// run runs the job and then any runs queued in the meantime.
+func (s *Scheduler) run(ctx context.Context, job *scheduledJob, scheduled time.Time) {
+	for {
+		result, err := s.runner.RunWithOptions(job.NewCmd(ctx), job.Options...)
+		s.settings.OnRun(ScheduledRun{
+			Job:           job.Name,
+			ScheduledTime: scheduled,
+			Result:        result,
+			Err:           err,
+		})
+
+		job.mutex.Lock()
+		if len(job.queued) == 0 || ctx.Err() != nil {
+			job.queued = nil
+			job.running--
+			job.mutex.Unlock()
+			return
+		}
+		scheduled = job.queued[0]
+		job.queued = job.queued[1:]
+		job.mutex.Unlock()
+	}
+}
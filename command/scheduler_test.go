@@ -0,0 +1,192 @@
+package command
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	waiters []fakeClockWaiter
+}
+
+type fakeClockWaiter struct {
+	deadline time.Time
+	channel  chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	channel := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeClockWaiter{
+		deadline: c.now.Add(d),
+		channel:  channel,
+	})
+	return channel
+}
+
+// advance waits for n waiters to be registered, and then
+// moves the clock forward firing the waiters due.
+func (c *fakeClock) advance(t *testing.T, n int, d time.Duration) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return len(c.waiters) >= n
+	}, time.Second, time.Millisecond)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, waiter := range c.waiters {
+		if waiter.deadline.After(c.now) {
+			waiters = append(waiters, waiter)
+			continue
+		}
+		waiter.channel <- c.now
+	}
+	c.waiters = waiters
+}
+
+// blockingRunner runs commands until released.
+type blockingRunner struct {
+	started chan string
+	release chan struct{}
+}
+
+func (r *blockingRunner) RunWithOptions(cmd *exec.Cmd, _ ...OptionSetter) (Result, error) {
+	r.started <- cmd.Args[1]
+	<-r.release
+	return Result{Output: cmd.Args[1]}, nil
+}
+
+func Test_Scheduler_Run(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.January, 7, 10, 7, 30, 0, time.UTC)
+
+	testCases := map[string]struct {
+		overlap       OverlapPolicy
+		scheduledRuns []time.Time
+		skipped       bool
+	}{
+		"skip": {
+			overlap: OverlapSkip,
+			scheduledRuns: []time.Time{
+				time.Date(2023, time.January, 7, 10, 8, 0, 0, time.UTC),
+			},
+			skipped: true,
+		},
+		"queue": {
+			overlap: OverlapQueue,
+			scheduledRuns: []time.Time{
+				time.Date(2023, time.January, 7, 10, 8, 0, 0, time.UTC),
+				time.Date(2023, time.January, 7, 10, 9, 0, 0, time.UTC),
+			},
+		},
+		"allow": {
+			overlap: OverlapAllow,
+			scheduledRuns: []time.Time{
+				time.Date(2023, time.January, 7, 10, 8, 0, 0, time.UTC),
+				time.Date(2023, time.January, 7, 10, 9, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := &fakeClock{now: start}
+			runner := &blockingRunner{
+				started: make(chan string),
+				release: make(chan struct{}),
+			}
+			runs := make(chan ScheduledRun, 10)
+			scheduler := NewScheduler(runner, SchedulerSettings{
+				Clock: clock,
+				OnRun: func(run ScheduledRun) { runs <- run },
+			})
+			err := scheduler.Add(ScheduledJob{
+				Name:     "cleanup",
+				Schedule: "* * * * *",
+				NewCmd: func(ctx context.Context) *exec.Cmd {
+					return exec.CommandContext(ctx, "echo", "cleanup")
+				},
+				Overlap: testCase.overlap,
+			})
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				scheduler.Run(ctx)
+			}()
+
+			// First run at 10:08:00
+			clock.advance(t, 1, 30*time.Second)
+			assert.Equal(t, "cleanup", <-runner.started)
+
+			// Second run at 10:09:00 while the first run is still running
+			clock.advance(t, 1, time.Minute)
+			switch testCase.overlap {
+			case OverlapSkip:
+				run := <-runs
+				assert.ErrorIs(t, run.Err, ErrScheduledRunSkipped)
+				assert.Equal(t, time.Date(2023, time.January, 7, 10, 9, 0, 0, time.UTC),
+					run.ScheduledTime)
+				runner.release <- struct{}{}
+			case OverlapQueue:
+				runner.release <- struct{}{}
+				<-runner.started
+				runner.release <- struct{}{}
+			case OverlapAllow:
+				<-runner.started
+				runner.release <- struct{}{}
+				runner.release <- struct{}{}
+			}
+
+			var scheduledRuns []time.Time
+			for range testCase.scheduledRuns {
+				run := <-runs
+				assert.NoError(t, run.Err)
+				assert.Equal(t, "cleanup", run.Job)
+				assert.Equal(t, "cleanup", run.Result.Output)
+				scheduledRuns = append(scheduledRuns, run.ScheduledTime)
+			}
+			assert.ElementsMatch(t, testCase.scheduledRuns, scheduledRuns)
+
+			cancel()
+			<-done
+		})
+	}
+}
+
+func Test_Scheduler_Add(t *testing.T) {
+	t.Parallel()
+
+	scheduler := NewScheduler(nil, SchedulerSettings{})
+
+	err := scheduler.Add(ScheduledJob{Name: "x", Schedule: "* *"})
+
+	assert.ErrorIs(t, err, ErrCronFieldsCount)
+	assert.EqualError(t, err, "parsing schedule of job x: cron expression fields "+
+		"count is not valid: expected 5 or 6 fields but got 2")
+}
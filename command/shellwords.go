@@ -0,0 +1,175 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var (
+	ErrSingleQuoteUnterminated = errors.New("single quote not terminated")
+	ErrDoubleQuoteUnterminated = errors.New("double quote not terminated")
+	ErrBackslashTrailing       = errors.New("trailing backslash")
+	ErrCommandEmpty            = errors.New("command is empty")
+)
+
+// SplitShellWords splits a string into words following the POSIX shell
+// quoting rules: words are separated by unquoted blanks and newlines,
+// characters in single quotes are all literal, characters in double
+// quotes are literal except for backslash escaping $, `, ", \ and
+// newline, and unquoted backslashes escape the next character.
+// A backslash followed by a newline is removed, outside single quotes.
+// No expansion or substitution is done, so characters such as $, *,
+// |, ; or # are kept literally in the words.
+func SplitShellWords(s string) (words []string, err error) {
+	const (
+		stateUnquoted = iota
+		stateSingleQuoted
+		stateDoubleQuoted
+	)
+
+	var word strings.Builder
+	inWord := false
+	state := stateUnquoted
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch state {
+		case stateSingleQuoted:
+			if r == '\'' {
+				state = stateUnquoted
+				continue
+			}
+			word.WriteRune(r)
+		case stateDoubleQuoted:
+			switch r {
+			case '"':
+				state = stateUnquoted
+			case '\\':
+				if i+1 == len(runes) {
+					return nil, fmt.Errorf("%w: at position %d", ErrDoubleQuoteUnterminated, i)
+				}
+				next := runes[i+1]
+				switch next {
+				case '$', '`', '"', '\\':
+					word.WriteRune(next)
+					i++
+				case '\n':
+					i++
+				default:
+					word.WriteRune(r)
+				}
+			default:
+				word.WriteRune(r)
+			}
+		default: // unquoted
+			switch r {
+			case ' ', '\t', '\n':
+				if inWord {
+					words = append(words, word.String())
+					word.Reset()
+					inWord = false
+				}
+			case '\'':
+				state = stateSingleQuoted
+				inWord = true
+			case '"':
+				state = stateDoubleQuoted
+				inWord = true
+			case '\\':
+				if i+1 == len(runes) {
+					return nil, fmt.Errorf("%w: at position %d", ErrBackslashTrailing, i)
+				}
+				i++
+				if runes[i] == '\n' {
+					continue
+				}
+				word.WriteRune(runes[i])
+				inWord = true
+			default:
+				word.WriteRune(r)
+				inWord = true
+			}
+		}
+	}
+
+	switch state {
+	case stateSingleQuoted:
+		return nil, ErrSingleQuoteUnterminated
+	case stateDoubleQuoted:
+		return nil, ErrDoubleQuoteUnterminated
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// ParseCommand parses a command string into an *exec.Cmd,
+// splitting it into words with SplitShellWords.
+func ParseCommand(s string) (cmd *exec.Cmd, err error) {
+	return ParseCommandContext(context.Background(), s)
+}
+
+// ParseCommandContext parses a command string into an *exec.Cmd
+// using the context given, splitting it into words with SplitShellWords.
+func ParseCommandContext(ctx context.Context, s string) (cmd *exec.Cmd, err error) {
+	words, err := SplitShellWords(s)
+	if err != nil {
+		return nil, fmt.Errorf("splitting command: %w", err)
+	} else if len(words) == 0 {
+		return nil, ErrCommandEmpty
+	}
+	return exec.CommandContext(ctx, words[0], words[1:]...), nil
+}
+
+// QuoteShellWords quotes and joins the words given, such that the
+// string returned is split back into the same words by SplitShellWords
+// or by a POSIX shell.
+func QuoteShellWords(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = quoteShellWord(word)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// QuoteCommand returns the arguments of the command given quoted with
+// QuoteShellWords, to log the command in a reproducible way.
+func QuoteCommand(cmd *exec.Cmd) string {
+	if len(cmd.Args) == 0 {
+		return quoteShellWord(cmd.Path)
+	}
+	return QuoteShellWords(cmd.Args)
+}
+
+func quoteShellWord(word string) string {
+	if word == "" {
+		return "''"
+	}
+
+	safe := true
+	for _, r := range word {
+		if !isShellSafe(r) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return word
+	}
+
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+func isShellSafe(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	default:
+		return strings.ContainsRune("_@%+=:,./-", r)
+	}
+}
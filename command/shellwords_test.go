@@ -0,0 +1,120 @@
+package command
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SplitShellWords(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		s     string
+		words []string
+		err   error
+	}{
+		"empty": {},
+		"blanks only": {
+			s: " \t\n ",
+		},
+		"simple words": {
+			s:     "  ls -la\t/tmp\n",
+			words: []string{"ls", "-la", "/tmp"},
+		},
+		"single quotes": {
+			s:     `echo 'hello  world' 'a\b' '"'`,
+			words: []string{"echo", "hello  world", `a\b`, `"`},
+		},
+		"double quotes": {
+			s:     `echo "hello  'world'" "\$HOME \"x\" \\ \a" "a\` + "\n" + `b"`,
+			words: []string{"echo", "hello  'world'", `$HOME "x" \ \a`, "ab"},
+		},
+		"backslash escapes": {
+			s:     `echo hello\ world \'x\' a\` + "\n" + `b`,
+			words: []string{"echo", "hello world", "'x'", "ab"},
+		},
+		"empty quoted words": {
+			s:     `printf '' ""`,
+			words: []string{"printf", "", ""},
+		},
+		"concatenated word": {
+			s:     `a'b c'"d e"f`,
+			words: []string{"ab cd ef"},
+		},
+		"no expansion": {
+			s:     `echo $HOME * | cat; # x`,
+			words: []string{"echo", "$HOME", "*", "|", "cat;", "#", "x"},
+		},
+		"unterminated single quote": {
+			s:   `echo 'hello`,
+			err: ErrSingleQuoteUnterminated,
+		},
+		"unterminated double quote": {
+			s:   `echo "hello`,
+			err: ErrDoubleQuoteUnterminated,
+		},
+		"unterminated double quote after backslash": {
+			s:   `echo "hello\`,
+			err: ErrDoubleQuoteUnterminated,
+		},
+		"trailing backslash": {
+			s:   `echo hello\`,
+			err: ErrBackslashTrailing,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			words, err := SplitShellWords(testCase.s)
+
+			assert.ErrorIs(t, err, testCase.err)
+			assert.Equal(t, testCase.words, words)
+		})
+	}
+}
+
+func Test_QuoteShellWords(t *testing.T) {
+	t.Parallel()
+
+	words := []string{"echo", "", "a b", "it's", `"$HOME"`, "safe_-./:=@%+,", "tab\there", "é"}
+
+	quoted := QuoteShellWords(words)
+
+	const expected = `echo '' 'a b' 'it'\''s' '"$HOME"' safe_-./:=@%+, 'tab	here' 'é'`
+	assert.Equal(t, expected, quoted)
+	splitWords, err := SplitShellWords(quoted)
+	require.NoError(t, err)
+	assert.Equal(t, words, splitWords)
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	// A POSIX shell splits the quoted string into the same words.
+	script := "set -- " + quoted + `; for arg in "$@"; do printf '[%s]\n' "$arg"; done`
+	output, err := New().Run(exec.Command("sh", "-c", script))
+	require.NoError(t, err)
+	assert.Equal(t, "[echo]\n[]\n[a b]\n[it's]\n[\"$HOME\"]\n[safe_-./:=@%+,]\n[tab\there]\n[é]", output)
+}
+
+func Test_ParseCommand(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseCommand("   ")
+	assert.ErrorIs(t, err, ErrCommandEmpty)
+
+	_, err = ParseCommand("echo 'x")
+	assert.ErrorIs(t, err, ErrSingleQuoteUnterminated)
+
+	cmd, err := ParseCommandContext(context.Background(), `openvpn --config "/etc/my vpn.conf" --verb 3`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openvpn", "--config", "/etc/my vpn.conf", "--verb", "3"}, cmd.Args)
+	assert.Equal(t, `openvpn --config '/etc/my vpn.conf' --verb 3`, QuoteCommand(cmd))
+}
@@ -2,19 +2,56 @@ package command
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"io"
 	"os"
 	"os/exec"
+	"sync"
 )
 
 // Start launches a command and streams stdout and stderr to channels.
 // All the channels returned are ready only and won't be closed
-// if the command fails later.
+// if the command fails later. The default options of the cmder are
+// applied, and modify the command, as with StartProcess.
 func (c *Cmder) Start(cmd *exec.Cmd) (
 	stdoutLines, stderrLines <-chan string,
 	waitError <-chan error, startErr error) {
-	return start(cmd)
+	process, err := c.StartProcess(cmd)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stdoutLines, stderrLines, waitError = forwardProcess(process)
+	return stdoutLines, stderrLines, waitError, nil
+}
+
+// forwardProcess forwards the output lines of the process to channels
+// which are never closed, and sends its wait error once its output
+// streams are drained.
+func forwardProcess(process *Process) (stdoutLines, stderrLines <-chan string,
+	waitError <-chan error) {
+	stdoutLinesCh := make(chan string)
+	stderrLinesCh := make(chan string)
+	waitErrorCh := make(chan error)
+
+	var wg sync.WaitGroup
+	forward := func(input <-chan string, output chan<- string) {
+		defer wg.Done()
+		for line := range input {
+			output <- line
+		}
+	}
+	const streams = 2
+	wg.Add(streams)
+	go forward(process.Stdout(), stdoutLinesCh)
+	go forward(process.Stderr(), stderrLinesCh)
+
+	go func() {
+		wg.Wait()
+		waitErrorCh <- process.Wait()
+	}()
+
+	return stdoutLinesCh, stderrLinesCh, waitErrorCh
 }
 
 func start(cmd execCmd) (stdoutLines, stderrLines <-chan string,
@@ -31,7 +68,7 @@ func start(cmd execCmd) (stdoutLines, stderrLines <-chan string,
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	go streamToChannel(stdoutReady, stop, stdoutDone, stdout, stdoutLinesCh)
+	go streamToChannel(stdoutReady, stop, stdoutDone, stdout, stdoutLinesCh, defaultMaxLineSize)
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
@@ -40,7 +77,7 @@ func start(cmd execCmd) (stdoutLines, stderrLines <-chan string,
 		<-stdoutDone
 		return nil, nil, nil, err
 	}
-	go streamToChannel(stderrReady, stop, stderrDone, stderr, stderrLinesCh)
+	go streamToChannel(stderrReady, stop, stderrDone, stderr, stderrLinesCh, defaultMaxLineSize)
 
 	err = cmd.Start()
 	if err != nil {
@@ -66,15 +103,20 @@ func start(cmd execCmd) (stdoutLines, stderrLines <-chan string,
 	return stdoutLinesCh, stderrLinesCh, waitErrorCh, nil
 }
 
+const defaultMaxLineSize = 1 * 1024 * 1024 // 1MB
+
 func streamToChannel(ready chan<- struct{},
 	stop <-chan struct{}, done chan<- struct{},
-	stream io.Reader, lines chan<- string) {
+	stream io.Reader, lines chan<- string, maxLineSize int) {
 	defer close(done)
 	close(ready)
 	scanner := bufio.NewScanner(stream)
-	lineBuffer := make([]byte, bufio.MaxScanTokenSize) // 64KB
-	const maxCapacity = 1 * 1024 * 1024                // 1MB
-	scanner.Buffer(lineBuffer, maxCapacity)
+	lineBuffer := make([]byte, min(bufio.MaxScanTokenSize, maxLineSize)) // 64KB at most
+	// Two more bytes than the maximum line size are buffered to detect
+	// if a line ends right at the maximum line size with "\n" or "\r\n".
+	const maxLineEndingSize = 2
+	scanner.Buffer(lineBuffer, maxLineSize+maxLineEndingSize)
+	scanner.Split(newLineSplitter(maxLineSize))
 
 	for scanner.Scan() {
 		// scanner is closed if the context is canceled
@@ -95,3 +137,33 @@ func streamToChannel(ready chan<- struct{},
 		lines <- "stream error: " + err.Error()
 	}
 }
+
+// newLineSplitter returns a split function splitting lines as
+// bufio.ScanLines does, but splitting lines longer than maxLineSize,
+// excluding their line ending, into continuation chunks of maxLineSize
+// bytes instead of failing.
+func newLineSplitter(maxLineSize int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		lineEnd := bytes.IndexByte(data, '\n')
+		if lineEnd == -1 && atEOF {
+			lineEnd = len(data)
+		}
+
+		switch {
+		case lineEnd >= 0:
+			lineLength := lineEnd
+			if lineLength > 0 && data[lineLength-1] == '\r' {
+				lineLength--
+			}
+			if lineLength <= maxLineSize {
+				return bufio.ScanLines(data, atEOF)
+			}
+		case len(data) <= maxLineSize,
+			len(data) == maxLineSize+1 && data[maxLineSize] == '\r':
+			// The line may still end within the maximum line size,
+			// so request more data.
+			return 0, nil, nil
+		}
+		return maxLineSize, data[:maxLineSize], nil
+	}
+}
@@ -117,3 +117,77 @@ func Test_start(t *testing.T) { //nolint:cyclop
 		})
 	}
 }
+
+func Test_streamToChannel(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		input       string
+		maxLineSize int
+		lines       []string
+	}{
+		"short lines": {
+			input:       "ab\r\ncd\n",
+			maxLineSize: 4,
+			lines:       []string{"ab", "cd"},
+		},
+		"line of maximum size": {
+			input:       "abcd\nef",
+			maxLineSize: 4,
+			lines:       []string{"abcd", "ef"},
+		},
+		"long lines": {
+			input:       "abcdefghij\nklmnop",
+			maxLineSize: 4,
+			lines:       []string{"abcd", "efgh", "ij", "klmn", "op"},
+		},
+		"line of maximum size ending with CRLF": {
+			input:       "abcd\r\nef\r\nghij\r",
+			maxLineSize: 4,
+			lines:       []string{"abcd", "ef", "ghij"},
+		},
+		"long line with carriage return": {
+			input:       "abcd\ref\n",
+			maxLineSize: 4,
+			lines:       []string{"abcd", "\ref"},
+		},
+		"zero maximum size": {
+			input:       "ab\ncd",
+			maxLineSize: 0,
+			lines:       []string{"ab", "cd"},
+		},
+		"negative maximum size": {
+			input:       "ab\ncd",
+			maxLineSize: -1,
+			lines:       []string{"ab", "cd"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ready := make(chan struct{})
+			stop := make(chan struct{})
+			done := make(chan struct{})
+			linesCh := make(chan string)
+			maxLineSize := newOptions([]OptionSetter{MaxLineSize(testCase.maxLineSize)}).maxLineSize
+			go streamToChannel(ready, stop, done, strings.NewReader(testCase.input),
+				linesCh, maxLineSize)
+
+			var lines []string
+			for {
+				select {
+				case line := <-linesCh:
+					lines = append(lines, line)
+					continue
+				case <-done:
+				}
+				break
+			}
+
+			assert.Equal(t, testCase.lines, lines)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+var ErrTimedOut = errors.New("command timed out")
+
+// watchTimeout kills the process once the timeout elapses.
+// It returns a function to stop watching and to report if the
+// timeout was reached. A zero timeout disables the watch.
+func watchTimeout(cmd processCmd, timeout time.Duration) (stop func() (timedOut bool)) {
+	if timeout == 0 {
+		return func() bool { return false }
+	}
+
+	var timedOut atomic.Bool
+	timer := time.AfterFunc(timeout, func() {
+		timedOut.Store(true)
+		_ = cmd.Signal(os.Kill)
+	})
+	return func() bool {
+		timer.Stop()
+		return timedOut.Load()
+	}
+}
+
+func wrapTimeoutError(err error, timeout time.Duration) error {
+	if err == nil {
+		return fmt.Errorf("%w: after %s", ErrTimedOut, timeout)
+	}
+	return fmt.Errorf("%w: after %s: %w", ErrTimedOut, timeout, err)
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"os"
+	"time"
+)
+
+// Usage contains resource usage information of an exited command.
+type Usage struct {
+	// UserTime is the user CPU time used.
+	UserTime time.Duration
+	// SystemTime is the system CPU time used.
+	SystemTime time.Duration
+	// MaxRSS is the maximum resident set size in bytes.
+	// It is only set on Linux.
+	MaxRSS int64
+	// VoluntaryContextSwitches is the number of voluntary
+	// context switches. It is only set on Linux.
+	VoluntaryContextSwitches int64
+	// InvoluntaryContextSwitches is the number of involuntary
+	// context switches. It is only set on Linux.
+	InvoluntaryContextSwitches int64
+}
+
+func usageFromState(state *os.ProcessState) (usage Usage) {
+	if state == nil {
+		return usage
+	}
+	usage.UserTime = state.UserTime()
+	usage.SystemTime = state.SystemTime()
+	setSysUsage(&usage, state.SysUsage())
+	return usage
+}
@@ -0,0 +1,14 @@
+package command
+
+import "syscall"
+
+func setSysUsage(usage *Usage, sysUsage any) {
+	rusage, ok := sysUsage.(*syscall.Rusage)
+	if !ok {
+		return
+	}
+	const kibiByte = 1024
+	usage.MaxRSS = rusage.Maxrss * kibiByte
+	usage.VoluntaryContextSwitches = rusage.Nvcsw
+	usage.InvoluntaryContextSwitches = rusage.Nivcsw
+}
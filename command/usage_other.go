@@ -0,0 +1,5 @@
+//go:build !linux
+
+package command
+
+func setSysUsage(*Usage, any) {}
@@ -0,0 +1,159 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/qdm12/golibs/files"
+)
+
+type credential struct {
+	uid    uint32
+	gid    uint32
+	groups []uint32
+}
+
+// RunAs runs the command as the user ID and group ID given, with the
+// supplementary groups given. If no supplementary group is given, the
+// command runs with no supplementary group. This is not supported on
+// Windows, and usually requires the current process to run as root.
+func RunAs(uid, gid uint32, groups ...uint32) OptionSetter {
+	return func(o *options) {
+		o.credential = &credential{uid: uid, gid: gid, groups: groups}
+		o.credentialFromFile = ""
+	}
+}
+
+// RunAsFileOwner runs the command as the user ID and group ID owning
+// the file or directory at the path given, with no supplementary group.
+// See RunAs for the limitations.
+func RunAsFileOwner(path string) OptionSetter {
+	return func(o *options) {
+		o.credential = nil
+		o.credentialFromFile = path
+	}
+}
+
+// EnvAllowlist only passes the environment variables with the
+// names given to the command, taken from the command environment
+// if it is set, or from the current process environment otherwise.
+func EnvAllowlist(names ...string) OptionSetter {
+	return func(o *options) {
+		o.envAllowlist = names
+		if o.envAllowlist == nil {
+			o.envAllowlist = []string{}
+		}
+	}
+}
+
+// WorkingDir runs the command in the existing directory given.
+// If the command runs as another user, the directory must be
+// accessible by this user.
+func WorkingDir(path string) OptionSetter {
+	return func(o *options) {
+		o.workingDir = path
+	}
+}
+
+// NoNewPrivileges sets the no_new_privs attribute of the command,
+// such that it and its children cannot gain privileges, for example
+// through setuid binaries. This is only supported on Linux.
+func NoNewPrivileges() OptionSetter {
+	return func(o *options) {
+		o.noNewPrivileges = true
+	}
+}
+
+var (
+	ErrWorkingDirNotDirectory  = errors.New("working directory is not a directory")
+	ErrWorkingDirNotAccessible = errors.New("working directory is not accessible")
+)
+
+// newCmdAdapter applies the process options to the command given
+// and returns a processCmd wrapping it.
+func newCmdAdapter(cmd *exec.Cmd, options options) (adapter cmdAdapter, err error) {
+	adapter = cmdAdapter{
+		Cmd:             cmd,
+		noNewPrivileges: options.noNewPrivileges,
+		init:            options.init,
+		pipeWriters:     new([]*os.File),
+	}
+
+	if options.credentialFromFile != "" {
+		uid, gid, err := getOwnership(options.credentialFromFile)
+		if err != nil {
+			return adapter, fmt.Errorf("getting ownership: %w", err)
+		}
+		options.credential = &credential{uid: uint32(uid), gid: uint32(gid)}
+	}
+
+	if options.credential != nil {
+		err = setCredential(cmd, *options.credential)
+		if err != nil {
+			return adapter, err
+		}
+	}
+
+	if options.envAllowlist != nil {
+		cmd.Env = filterEnv(cmd.Env, options.envAllowlist)
+	}
+
+	if options.workingDir != "" {
+		err = checkWorkingDir(options.workingDir, options.credential)
+		if err != nil {
+			return adapter, err
+		}
+		cmd.Dir = options.workingDir
+	}
+
+	if options.audit != nil {
+		adapter.audit = newAuditRun(options)
+	}
+
+	return adapter, nil
+}
+
+func filterEnv(env, allowlist []string) (filtered []string) {
+	if env == nil {
+		env = os.Environ()
+	}
+
+	allowed := make(map[string]struct{}, len(allowlist))
+	for _, name := range allowlist {
+		allowed[name] = struct{}{}
+	}
+
+	filtered = make([]string, 0, len(allowlist))
+	for _, keyValue := range env {
+		key, _, _ := strings.Cut(keyValue, "=")
+		if _, ok := allowed[key]; ok {
+			filtered = append(filtered, keyValue)
+		}
+	}
+	return filtered
+}
+
+func checkWorkingDir(path string, credential *credential) error {
+	isDirectory, err := files.IsDirectory(path)
+	if err != nil {
+		return fmt.Errorf("checking working directory: %w", err)
+	} else if !isDirectory {
+		return fmt.Errorf("%w: %s", ErrWorkingDirNotDirectory, path)
+	}
+
+	if credential == nil {
+		return nil
+	}
+
+	accessible, err := files.IsExecutable(path, int(credential.uid), int(credential.gid))
+	if err != nil {
+		return fmt.Errorf("checking working directory access: %w", err)
+	} else if !accessible {
+		return fmt.Errorf("%w: %s for user %d:%d", ErrWorkingDirNotAccessible,
+			path, credential.uid, credential.gid)
+	}
+	return nil
+}
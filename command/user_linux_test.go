@@ -0,0 +1,63 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Cmder_RunWithOptions_noNewPrivileges(t *testing.T) {
+	t.Parallel()
+
+	cmder := New()
+
+	cmd := exec.Command("grep", "NoNewPrivs", "/proc/self/status")
+	result, err := cmder.RunWithOptions(cmd, NoNewPrivileges())
+	require.NoError(t, err)
+	assert.Equal(t, "NoNewPrivs:\t1", result.Output)
+
+	cmd = exec.Command("grep", "NoNewPrivs", "/proc/self/status")
+	result, err = cmder.RunWithOptions(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "NoNewPrivs:\t0", result.Output)
+}
+
+func Test_Cmder_RunWithOptions_runAsFileOwner(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() != 0 {
+		t.Skip("requires running as root")
+	}
+
+	const uid, gid = 65534, 65534
+	// t.TempDir() parent directory is not accessible by other users.
+	dirPath, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := os.RemoveAll(dirPath)
+		assert.NoError(t, err)
+	})
+	err = os.Chmod(dirPath, 0755) //nolint:gosec
+	require.NoError(t, err)
+	filePath := filepath.Join(dirPath, "file")
+	err = os.WriteFile(filePath, nil, 0600)
+	require.NoError(t, err)
+	err = os.Chown(filePath, uid, gid)
+	require.NoError(t, err)
+
+	cmd := exec.Command("sh", "-c", `id -u; id -g; id -G; pwd; env | cut -d= -f1`)
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "SECRET=x"}
+	result, err := New().RunWithOptions(cmd, RunAsFileOwner(filePath),
+		WorkingDir(dirPath), EnvAllowlist("PATH"))
+	require.NoError(t, err)
+
+	lines := strings.Split(result.Output, "\n")
+	require.GreaterOrEqual(t, len(lines), 5)
+	assert.Equal(t, []string{"65534", "65534", "65534", dirPath}, lines[:4])
+	assert.NotContains(t, lines[4:], "SECRET")
+}
@@ -0,0 +1,83 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_filterEnv(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		env       []string
+		allowlist []string
+		filtered  []string
+	}{
+		"empty allowlist": {
+			env:       []string{"A=1"},
+			allowlist: []string{},
+			filtered:  []string{},
+		},
+		"filtered": {
+			env:       []string{"A=1", "B=2", "AB=3", "C", "D=x=y"},
+			allowlist: []string{"A", "C", "D"},
+			filtered:  []string{"A=1", "C", "D=x=y"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filtered := filterEnv(testCase.env, testCase.allowlist)
+
+			assert.Equal(t, testCase.filtered, filtered)
+		})
+	}
+}
+
+func Test_newCmdAdapter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("environment from process", func(t *testing.T) {
+		t.Parallel()
+
+		cmd := exec.Command("env")
+		_, err := newCmdAdapter(cmd, newOptions([]OptionSetter{EnvAllowlist("PATH")}))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"PATH=" + os.Getenv("PATH")}, cmd.Env)
+	})
+
+	t.Run("working directory is a file", func(t *testing.T) {
+		t.Parallel()
+
+		filePath := filepath.Join(t.TempDir(), "file")
+		err := os.WriteFile(filePath, nil, 0600)
+		require.NoError(t, err)
+
+		cmd := exec.Command("pwd")
+		_, err = newCmdAdapter(cmd, newOptions([]OptionSetter{WorkingDir(filePath)}))
+		assert.ErrorIs(t, err, ErrWorkingDirNotDirectory)
+	})
+
+	t.Run("working directory", func(t *testing.T) {
+		t.Parallel()
+
+		dirPath := t.TempDir()
+		cmd := exec.Command("pwd")
+		adapter, err := newCmdAdapter(cmd, newOptions([]OptionSetter{
+			WorkingDir(dirPath), NoNewPrivileges(),
+		}))
+		require.NoError(t, err)
+
+		assert.Equal(t, dirPath, cmd.Dir)
+		assert.True(t, adapter.noNewPrivileges)
+	})
+}
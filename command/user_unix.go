@@ -0,0 +1,30 @@
+//go:build !windows
+
+package command
+
+import (
+	"os/exec"
+	"syscall"
+
+	"github.com/qdm12/golibs/files"
+)
+
+func getOwnership(path string) (uid, gid int, err error) {
+	return files.GetOwnership(path)
+}
+
+func setCredential(cmd *exec.Cmd, credential credential) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	groups := credential.groups
+	if groups == nil {
+		groups = []uint32{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    credential.uid,
+		Gid:    credential.gid,
+		Groups: groups,
+	}
+	return nil
+}
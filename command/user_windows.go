@@ -0,0 +1,16 @@
+package command
+
+import (
+	"errors"
+	"os/exec"
+)
+
+var ErrCredentialNotSupported = errors.New("running as another user is not supported on Windows")
+
+func getOwnership(string) (uid, gid int, err error) {
+	return 0, 0, ErrCredentialNotSupported
+}
+
+func setCredential(*exec.Cmd, credential) error {
+	return ErrCredentialNotSupported
+}
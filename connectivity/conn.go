@@ -0,0 +1,62 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	urlpkg "net/url"
+	"strconv"
+	"strings"
+)
+
+var ErrAddressPortMissing = errors.New("address port is missing")
+
+// parseAddress parses an address given either as host:port or as
+// an URL, and returns it as host:port. If the URL has no port, the
+// port of its scheme for the network given is used, for example
+// port 25 for the smtp scheme with the tcp network.
+func parseAddress(network, address string) (hostPort string, err error) {
+	if !strings.Contains(address, "://") {
+		_, _, err = net.SplitHostPort(address)
+		if err != nil {
+			return "", fmt.Errorf("parsing address: %w", err)
+		}
+		return address, nil
+	}
+
+	u, err := urlpkg.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("parsing url: %w", err)
+	}
+
+	port := u.Port()
+	if port == "" {
+		portNumber, err := net.LookupPort(network, u.Scheme)
+		if err != nil {
+			return "", fmt.Errorf("%w: for url %s: %w", ErrAddressPortMissing, address, err)
+		}
+		port = strconv.Itoa(portNumber)
+	}
+
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// closeOnDone closes the connection once the context is done, to
+// unblock any pending read or write. The stop function returned
+// should be called once the connection is no longer used.
+func closeOnDone(ctx context.Context, connection net.Conn) (stop func() bool) {
+	return context.AfterFunc(ctx, func() {
+		_ = connection.Close()
+	})
+}
+
+// contextOrError returns the context error if the context is done,
+// since the connection error is then due to it being closed, and
+// returns the error given otherwise.
+func contextOrError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package connectivity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseAddress(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		network    string
+		address    string
+		hostPort   string
+		errWrapped error
+		errMessage string
+	}{
+		"host port": {
+			network:  "tcp",
+			address:  "db.example.com:5432",
+			hostPort: "db.example.com:5432",
+		},
+		"ipv6 host port": {
+			network:  "udp",
+			address:  "[::1]:1194",
+			hostPort: "[::1]:1194",
+		},
+		"host without port": {
+			network:    "tcp",
+			address:    "db.example.com",
+			errMessage: "parsing address: address db.example.com: missing port in address",
+		},
+		"url with port": {
+			network:  "tcp",
+			address:  "postgres://user@db.example.com:5432/name",
+			hostPort: "db.example.com:5432",
+		},
+		"url with scheme port": {
+			network:  "tcp",
+			address:  "smtp://mail.example.com",
+			hostPort: "mail.example.com:25",
+		},
+		"url with unknown scheme": {
+			network:    "tcp",
+			address:    "unknownscheme://example.com",
+			errWrapped: ErrAddressPortMissing,
+			errMessage: "address port is missing: for url unknownscheme://example.com: " +
+				"lookup tcp/unknownscheme: unknown port",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			hostPort, err := parseAddress(testCase.network, testCase.address)
+
+			assert.Equal(t, testCase.hostPort, hostPort)
+			if testCase.errMessage == "" {
+				require.NoError(t, err)
+				return
+			}
+			if testCase.errWrapped != nil {
+				assert.ErrorIs(t, err, testCase.errWrapped)
+			}
+			assert.EqualError(t, err, testCase.errMessage)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	urlpkg "net/url"
+	"slices"
+	"strings"
+)
+
+// Resolver is a DNS resolver to lookup records of specific types.
+// It is implemented by *net.Resolver.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// DNSAssertions are the assertions on the DNS records of a domain
+// name. Each assertion is ignored if left to its zero value.
+type DNSAssertions struct {
+	// A is the exact set of IPv4 addresses expected.
+	A []netip.Addr
+	// AAAA is the exact set of IPv6 addresses expected.
+	AAAA []netip.Addr
+	// CNAME is the canonical name target expected.
+	CNAME string
+	// TXTContains are values each expected to be contained
+	// in at least one of the TXT records.
+	TXTContains []string
+	// MX, NS and SRV are set to true to expect at least one
+	// record of their type. SRV records are looked up for the
+	// domain name as is, such as _sip._tcp.example.com.
+	MX  bool
+	NS  bool
+	SRV bool
+}
+
+// NewDNSAssertChecker creates a new DNS assertion checker
+// using the resolver and assertions given.
+func NewDNSAssertChecker(resolver Resolver, assertions DNSAssertions) *DNSAssertChecker {
+	return &DNSAssertChecker{
+		resolver:   resolver,
+		assertions: assertions,
+	}
+}
+
+// DNSAssertChecker implements a checker to lookup DNS records
+// of domain names and verify them against assertions.
+type DNSAssertChecker struct {
+	resolver   Resolver
+	assertions DNSAssertions
+}
+
+// ParallelChecks verifies the DNS records of the domain name of each
+// of the urls match the assertions.
+// It returns a slice of errors with the same indexing and order as the
+// urls, meaning that some errors might be nil or not. You should ensure
+// to iterate over the errors and check each of them.
+func (c *DNSAssertChecker) ParallelChecks(ctx context.Context, urls []string) (errs []error) {
+	return parallelChecks(ctx, c, urls)
+}
+
+var (
+	ErrDNSAddressesMismatch = errors.New("DNS addresses do not match")
+	ErrDNSCNAMEMismatch     = errors.New("DNS CNAME does not match")
+	ErrDNSTXTNotFound       = errors.New("DNS TXT value not found")
+	ErrDNSRecordMissing     = errors.New("DNS record is missing")
+)
+
+// Check verifies the DNS records of the domain name of the url, which
+// can also be given as a domain name or as host:port, match the
+// assertions. Each failing assertion is returned as an error, joined
+// together.
+func (c *DNSAssertChecker) Check(ctx context.Context, url string) error {
+	domain, err := parseHost(url)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if c.assertions.A != nil {
+		errs = append(errs, c.checkAddresses(ctx, "ip4", domain, c.assertions.A))
+	}
+	if c.assertions.AAAA != nil {
+		errs = append(errs, c.checkAddresses(ctx, "ip6", domain, c.assertions.AAAA))
+	}
+	if c.assertions.CNAME != "" {
+		errs = append(errs, c.checkCNAME(ctx, domain))
+	}
+	if len(c.assertions.TXTContains) > 0 {
+		errs = append(errs, c.checkTXT(ctx, domain))
+	}
+	if c.assertions.MX {
+		records, err := c.resolver.LookupMX(ctx, domain)
+		errs = append(errs, checkPresence("MX", len(records), err))
+	}
+	if c.assertions.NS {
+		records, err := c.resolver.LookupNS(ctx, domain)
+		errs = append(errs, checkPresence("NS", len(records), err))
+	}
+	if c.assertions.SRV {
+		_, records, err := c.resolver.LookupSRV(ctx, "", "", domain)
+		errs = append(errs, checkPresence("SRV", len(records), err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *DNSAssertChecker) checkAddresses(ctx context.Context, network, domain string,
+	expected []netip.Addr) error {
+	received, err := c.resolver.LookupNetIP(ctx, network, domain)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		err = nil // no address is a valid answer to compare
+	}
+	if err != nil {
+		return fmt.Errorf("looking up %s addresses: %w", network, err)
+	}
+
+	expected = sortedUniqueAddresses(expected)
+	received = sortedUniqueAddresses(received)
+	if !slices.Equal(expected, received) {
+		return fmt.Errorf("%w: expected %s addresses %v and received %v",
+			ErrDNSAddressesMismatch, network, expected, received)
+	}
+	return nil
+}
+
+func sortedUniqueAddresses(addresses []netip.Addr) (result []netip.Addr) {
+	result = make([]netip.Addr, len(addresses))
+	for i, address := range addresses {
+		result[i] = address.Unmap()
+	}
+	slices.SortFunc(result, func(a, b netip.Addr) int { return a.Compare(b) })
+	return slices.Compact(result)
+}
+
+func (c *DNSAssertChecker) checkCNAME(ctx context.Context, domain string) error {
+	cname, err := c.resolver.LookupCNAME(ctx, domain)
+	if err != nil {
+		return fmt.Errorf("looking up CNAME: %w", err)
+	}
+
+	normalize := func(name string) string {
+		return strings.ToLower(strings.TrimSuffix(name, "."))
+	}
+	if normalize(cname) != normalize(c.assertions.CNAME) {
+		return fmt.Errorf("%w: expected %s and received %s",
+			ErrDNSCNAMEMismatch, c.assertions.CNAME, cname)
+	}
+	return nil
+}
+
+func (c *DNSAssertChecker) checkTXT(ctx context.Context, domain string) error {
+	records, err := c.resolver.LookupTXT(ctx, domain)
+	if err != nil {
+		return fmt.Errorf("looking up TXT: %w", err)
+	}
+
+	var errs []error
+	for _, value := range c.assertions.TXTContains {
+		found := slices.ContainsFunc(records, func(record string) bool {
+			return strings.Contains(record, value)
+		})
+		if !found {
+			errs = append(errs, fmt.Errorf("%w: %q in %d record(s)",
+				ErrDNSTXTNotFound, value, len(records)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkPresence(recordType string, count int, err error) error {
+	if err != nil {
+		return fmt.Errorf("looking up %s: %w", recordType, err)
+	} else if count == 0 {
+		return fmt.Errorf("%w: no %s record found", ErrDNSRecordMissing, recordType)
+	}
+	return nil
+}
+
+// parseHost returns the host of an url, or of an address given
+// as a domain name, an IP address or as host:port.
+func parseHost(address string) (host string, err error) {
+	if strings.Contains(address, "://") {
+		u, err := urlpkg.Parse(address)
+		if err != nil {
+			return "", fmt.Errorf("parsing url: %w", err)
+		}
+		return u.Hostname(), nil
+	}
+
+	host, _, err = net.SplitHostPort(address)
+	if err != nil {
+		// no port
+		return address, nil //nolint:nilerr
+	}
+	return host, nil
+}
@@ -0,0 +1,156 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResolver struct {
+	addresses map[string][]netip.Addr // keyed by network
+	cname     string
+	txt       []string
+	mx        []*net.MX
+	ns        []*net.NS
+	srv       []*net.SRV
+	err       error
+}
+
+func (r *fakeResolver) LookupNetIP(_ context.Context, network, _ string) ([]netip.Addr, error) {
+	return r.addresses[network], r.err
+}
+
+func (r *fakeResolver) LookupCNAME(context.Context, string) (string, error) {
+	return r.cname, r.err
+}
+
+func (r *fakeResolver) LookupTXT(context.Context, string) ([]string, error) {
+	return r.txt, r.err
+}
+
+func (r *fakeResolver) LookupMX(context.Context, string) ([]*net.MX, error) {
+	return r.mx, r.err
+}
+
+func (r *fakeResolver) LookupNS(context.Context, string) ([]*net.NS, error) {
+	return r.ns, r.err
+}
+
+func (r *fakeResolver) LookupSRV(context.Context, string, string, string) (string, []*net.SRV, error) {
+	return "", r.srv, r.err
+}
+
+func Test_DNSAssertChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	errDummy := errors.New("dummy")
+
+	resolver := &fakeResolver{
+		addresses: map[string][]netip.Addr{
+			"ip4": {netip.MustParseAddr("1.2.3.4"), netip.MustParseAddr("::ffff:5.6.7.8")},
+			"ip6": {netip.MustParseAddr("2001:db8::1")},
+		},
+		cname: "target.example.net.",
+		txt:   []string{"v=spf1 include:_spf.example.com ~all", "verification=abc"},
+		mx:    []*net.MX{{Host: "mail.example.com.", Pref: 10}},
+		ns:    []*net.NS{{Host: "ns1.example.com."}},
+	}
+
+	testCases := map[string]struct {
+		resolver    Resolver
+		assertions  DNSAssertions
+		errsWrapped []error
+		errMessage  string
+	}{
+		"all matching": {
+			resolver: resolver,
+			assertions: DNSAssertions{
+				A: []netip.Addr{netip.MustParseAddr("5.6.7.8"),
+					netip.MustParseAddr("1.2.3.4"), netip.MustParseAddr("1.2.3.4")},
+				AAAA:        []netip.Addr{netip.MustParseAddr("2001:db8::1")},
+				CNAME:       "Target.Example.NET",
+				TXTContains: []string{"v=spf1", "verification=abc"},
+				MX:          true,
+				NS:          true,
+			},
+		},
+		"mismatches": {
+			resolver: resolver,
+			assertions: DNSAssertions{
+				A:           []netip.Addr{netip.MustParseAddr("1.2.3.4")},
+				CNAME:       "other.example.net",
+				TXTContains: []string{"google-site-verification"},
+				SRV:         true,
+			},
+			errsWrapped: []error{ErrDNSAddressesMismatch, ErrDNSCNAMEMismatch,
+				ErrDNSTXTNotFound, ErrDNSRecordMissing},
+			errMessage: "DNS addresses do not match: expected ip4 addresses [1.2.3.4] " +
+				"and received [1.2.3.4 5.6.7.8]\n" +
+				"DNS CNAME does not match: expected other.example.net and received target.example.net.\n" +
+				"DNS TXT value not found: \"google-site-verification\" in 2 record(s)\n" +
+				"DNS record is missing: no SRV record found",
+		},
+		"no address": {
+			resolver: &fakeResolver{
+				err: &net.DNSError{Err: "no such host", IsNotFound: true},
+			},
+			assertions: DNSAssertions{
+				AAAA: []netip.Addr{},
+			},
+		},
+		"lookup error": {
+			resolver: &fakeResolver{err: errDummy},
+			assertions: DNSAssertions{
+				A:  []netip.Addr{},
+				MX: true,
+			},
+			errsWrapped: []error{errDummy},
+			errMessage:  "looking up ip4 addresses: dummy\nlooking up MX: dummy",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := NewDNSAssertChecker(testCase.resolver, testCase.assertions)
+
+			err := checker.Check(context.Background(), "https://example.com:8443/path")
+
+			if testCase.errMessage == "" {
+				require.NoError(t, err)
+				return
+			}
+			for _, errWrapped := range testCase.errsWrapped {
+				assert.ErrorIs(t, err, errWrapped)
+			}
+			assert.EqualError(t, err, testCase.errMessage)
+		})
+	}
+}
+
+func Test_parseHost(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"example.com":                "example.com",
+		"example.com:53":             "example.com",
+		"[2001:db8::1]:53":           "2001:db8::1",
+		"2001:db8::1":                "2001:db8::1",
+		"https://example.com:8443/x": "example.com",
+	}
+
+	for address, expectedHost := range testCases {
+		host, err := parseHost(address)
+		require.NoError(t, err)
+		assert.Equal(t, expectedHost, host, address)
+	}
+}
+
+var _ Resolver = (*net.Resolver)(nil)
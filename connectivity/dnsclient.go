@@ -0,0 +1,208 @@
+package connectivity
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+)
+
+// DNSExchanger sends a DNS query to a server and returns its response.
+// It is implemented by *DNSClient.
+type DNSExchanger interface {
+	Exchange(ctx context.Context, server string, query *DNSMessage) (response *DNSMessage, err error)
+}
+
+// NewDNSQuery creates a DNS query message with a random ID, recursion
+// desired and EDNS0 advertising a UDP payload size of 1232 bytes.
+func NewDNSQuery(name string, recordType DNSType) *DNSMessage {
+	const ednsUDPSize = 1232 // DNS flag day 2020 recommendation
+	return &DNSMessage{
+		ID:               uint16(rand.Uint32()), //nolint:gosec
+		RecursionDesired: true,
+		Questions: []DNSQuestion{{
+			Name:  name,
+			Type:  recordType,
+			Class: DNSClassINET,
+		}},
+		EDNS: &DNSEDNS{UDPSize: ednsUDPSize},
+	}
+}
+
+// NewDNSClient creates a new DNS client sending queries over UDP
+// and retrying them over TCP if the UDP response is truncated.
+// The timeout given applies to each exchange, and a zero timeout
+// means no timeout other than the context deadline.
+func NewDNSClient(dialer *net.Dialer, timeout time.Duration) *DNSClient {
+	return &DNSClient{
+		dialer:  dialer,
+		timeout: timeout,
+	}
+}
+
+// DNSClient is a DNS client querying specific DNS servers
+// using the DNS wire protocol over UDP and TCP.
+type DNSClient struct {
+	dialer  *net.Dialer
+	timeout time.Duration
+}
+
+var ErrDNSResponseMismatch = errors.New("DNS response does not match query")
+
+// Exchange sends the query to the server given as host:port, or as
+// host in which case port 53 is used, and returns its response.
+// The query is sent over UDP first, and sent again over TCP if the
+// UDP response is truncated.
+func (c *DNSClient) Exchange(ctx context.Context, server string,
+	query *DNSMessage) (response *DNSMessage, err error) {
+	packedQuery, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("packing query: %w", err)
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	address := withDefaultPort(server, "53")
+	response, err = c.exchangeUDP(ctx, address, query, packedQuery)
+	if err != nil {
+		return nil, fmt.Errorf("exchanging over UDP: %w", err)
+	} else if !response.Truncated {
+		return response, nil
+	}
+
+	response, err = c.exchangeTCP(ctx, address, query, packedQuery)
+	if err != nil {
+		return nil, fmt.Errorf("exchanging over TCP after truncated UDP response: %w", err)
+	}
+	return response, nil
+}
+
+func (c *DNSClient) exchangeUDP(ctx context.Context, address string,
+	query *DNSMessage, packedQuery []byte) (response *DNSMessage, err error) {
+	connection, err := c.dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("dialing: %w", err)
+	}
+	defer connection.Close()
+	stop := closeOnDone(ctx, connection)
+	defer stop()
+
+	_, err = connection.Write(packedQuery)
+	if err != nil {
+		return nil, fmt.Errorf("writing query: %w", contextOrError(ctx, err))
+	}
+
+	bufferSize := 512
+	if query.EDNS != nil {
+		bufferSize = max(bufferSize, int(query.EDNS.UDPSize))
+	}
+	buffer := make([]byte, bufferSize)
+	for {
+		n, err := connection.Read(buffer)
+		if err != nil {
+			return nil, fmt.Errorf("reading response: %w", contextOrError(ctx, err))
+		}
+
+		response, err = UnpackDNSMessage(buffer[:n])
+		if err != nil || checkDNSResponse(query, response) != nil {
+			// Ignore malformed, late or spoofed responses
+			// and keep on waiting for the response.
+			continue
+		}
+		return response, nil
+	}
+}
+
+func (c *DNSClient) exchangeTCP(ctx context.Context, address string,
+	query *DNSMessage, packedQuery []byte) (response *DNSMessage, err error) {
+	connection, err := c.dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("dialing: %w", err)
+	}
+	defer connection.Close()
+	stop := closeOnDone(ctx, connection)
+	defer stop()
+
+	response, err = exchangeStream(connection, packedQuery)
+	if err != nil {
+		return nil, contextOrError(ctx, err)
+	}
+
+	err = checkDNSResponse(query, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// exchangeStream exchanges a DNS query over a stream connection
+// such as TCP or TLS, where each message is prefixed with its
+// length on two bytes.
+func exchangeStream(connection io.ReadWriter, packedQuery []byte) (
+	response *DNSMessage, err error) {
+	const lengthSize = 2
+	message := make([]byte, lengthSize, lengthSize+len(packedQuery))
+	binary.BigEndian.PutUint16(message, uint16(len(packedQuery)))
+	message = append(message, packedQuery...)
+	_, err = connection.Write(message)
+	if err != nil {
+		return nil, fmt.Errorf("writing query: %w", err)
+	}
+
+	length := make([]byte, lengthSize)
+	_, err = io.ReadFull(connection, length)
+	if err != nil {
+		return nil, fmt.Errorf("reading response length: %w", err)
+	}
+	packedResponse := make([]byte, binary.BigEndian.Uint16(length))
+	_, err = io.ReadFull(connection, packedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	response, err = UnpackDNSMessage(packedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("unpacking response: %w", err)
+	}
+	return response, nil
+}
+
+func checkDNSResponse(query, response *DNSMessage) error {
+	switch {
+	case !response.Response:
+		return fmt.Errorf("%w: message is not a response", ErrDNSResponseMismatch)
+	case response.ID != query.ID:
+		return fmt.Errorf("%w: expected ID %d and received %d",
+			ErrDNSResponseMismatch, query.ID, response.ID)
+	case len(response.Questions) > 0 && len(query.Questions) > 0 &&
+		!sameDNSQuestion(response.Questions[0], query.Questions[0]):
+		return fmt.Errorf("%w: expected question %s %s and received %s %s",
+			ErrDNSResponseMismatch, query.Questions[0].Name, query.Questions[0].Type,
+			response.Questions[0].Name, response.Questions[0].Type)
+	}
+	return nil
+}
+
+func sameDNSQuestion(a, b DNSQuestion) bool {
+	return a.Type == b.Type && a.Class == b.Class &&
+		strings.EqualFold(strings.TrimSuffix(a.Name, "."), strings.TrimSuffix(b.Name, "."))
+}
+
+// withDefaultPort returns the address given with the default port
+// given appended if the address has no port.
+func withDefaultPort(address, defaultPort string) string {
+	_, _, err := net.SplitHostPort(address)
+	if err == nil {
+		return address
+	}
+	return net.JoinHostPort(strings.Trim(address, "[]"), defaultPort)
+}
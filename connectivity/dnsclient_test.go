@@ -0,0 +1,222 @@
+package connectivity
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dnsStandIn is a local in-process DNS server answering over
+// UDP and TCP on the same port with a handler function.
+type dnsStandIn struct {
+	address      string
+	handler      func(query *DNSMessage) (response *DNSMessage)
+	udpQueries   atomic.Int32
+	tcpQueries   atomic.Int32
+	dropUDPFirst atomic.Bool
+}
+
+func newDNSStandIn(t *testing.T, handler func(query *DNSMessage) *DNSMessage) *dnsStandIn {
+	t.Helper()
+
+	var packetConn net.PacketConn
+	var listener net.Listener
+	for packetConn == nil {
+		var err error
+		packetConn, err = net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		listener, err = net.Listen("tcp", packetConn.LocalAddr().String())
+		if err != nil { // TCP port already in use
+			_ = packetConn.Close()
+			packetConn = nil
+		}
+	}
+	t.Cleanup(func() {
+		_ = packetConn.Close()
+		_ = listener.Close()
+	})
+
+	standIn := &dnsStandIn{
+		address: packetConn.LocalAddr().String(),
+		handler: handler,
+	}
+	go standIn.serveUDP(packetConn)
+	go standIn.serveTCP(listener)
+	return standIn
+}
+
+// respond returns the packed response to the packed query given,
+// truncated to the maximum size given if it is not zero.
+func (s *dnsStandIn) respond(packedQuery []byte, maxSize int) []byte {
+	query, err := UnpackDNSMessage(packedQuery)
+	if err != nil {
+		return nil
+	}
+	response := s.handler(query)
+	response.ID = query.ID
+	response.Response = true
+	response.Questions = query.Questions
+	packed, err := response.Pack()
+	if err != nil {
+		panic(err)
+	}
+	if maxSize > 0 && len(packed) > maxSize {
+		truncated := *response
+		truncated.Truncated = true
+		truncated.Answers = nil
+		packed, _ = truncated.Pack()
+	}
+	return packed
+}
+
+func (s *dnsStandIn) serveUDP(packetConn net.PacketConn) {
+	buffer := make([]byte, 65535)
+	for {
+		n, address, err := packetConn.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+		s.udpQueries.Add(1)
+		maxSize := 512
+		query, err := UnpackDNSMessage(buffer[:n])
+		if err == nil && query.EDNS != nil {
+			maxSize = int(query.EDNS.UDPSize)
+		}
+		if s.dropUDPFirst.CompareAndSwap(true, false) {
+			// Send a response with a wrong ID first, as a late response would.
+			spoofed := s.respond(buffer[:n], maxSize)
+			spoofed[0]++
+			_, _ = packetConn.WriteTo(spoofed, address)
+		}
+		_, _ = packetConn.WriteTo(s.respond(buffer[:n], maxSize), address)
+	}
+}
+
+func (s *dnsStandIn) serveTCP(listener net.Listener) {
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		s.tcpQueries.Add(1)
+		go func() {
+			defer connection.Close()
+			length := make([]byte, 2)
+			_, err := io.ReadFull(connection, length)
+			if err != nil {
+				return
+			}
+			packedQuery := make([]byte, binary.BigEndian.Uint16(length))
+			_, err = io.ReadFull(connection, packedQuery)
+			if err != nil {
+				return
+			}
+			packed := s.respond(packedQuery, 0)
+			_, _ = connection.Write(binary.BigEndian.AppendUint16(nil, uint16(len(packed))))
+			_, _ = connection.Write(packed)
+		}()
+	}
+}
+
+func answerA(count int) func(query *DNSMessage) *DNSMessage {
+	return func(query *DNSMessage) *DNSMessage {
+		response := &DNSMessage{}
+		for i := 0; i < count; i++ {
+			response.Answers = append(response.Answers, DNSRecord{
+				Name:  query.Questions[0].Name,
+				Type:  DNSTypeA,
+				Class: DNSClassINET,
+				TTL:   60,
+				Data:  []byte{10, 0, byte(i >> 8), byte(i)},
+			})
+		}
+		return response
+	}
+}
+
+func Test_DNSClient_Exchange(t *testing.T) {
+	t.Parallel()
+
+	t.Run("udp", func(t *testing.T) {
+		t.Parallel()
+
+		standIn := newDNSStandIn(t, answerA(2))
+		standIn.dropUDPFirst.Store(true)
+		client := NewDNSClient(&net.Dialer{}, time.Second)
+
+		response, err := client.Exchange(context.Background(), standIn.address,
+			NewDNSQuery("example.com", DNSTypeA))
+
+		require.NoError(t, err)
+		require.Len(t, response.Answers, 2)
+		assert.Equal(t, "10.0.0.1", response.Answers[1].Value())
+		assert.Equal(t, int32(1), standIn.udpQueries.Load())
+		assert.Equal(t, int32(0), standIn.tcpQueries.Load())
+	})
+
+	t.Run("tcp fallback on truncation", func(t *testing.T) {
+		t.Parallel()
+
+		standIn := newDNSStandIn(t, answerA(200))
+		client := NewDNSClient(&net.Dialer{}, time.Second)
+
+		response, err := client.Exchange(context.Background(), standIn.address,
+			NewDNSQuery("example.com", DNSTypeA))
+
+		require.NoError(t, err)
+		assert.False(t, response.Truncated)
+		assert.Len(t, response.Answers, 200)
+		assert.Equal(t, int32(1), standIn.udpQueries.Load())
+		assert.Equal(t, int32(1), standIn.tcpQueries.Load())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = packetConn.Close() })
+		client := NewDNSClient(&net.Dialer{}, 50*time.Millisecond)
+
+		_, err = client.Exchange(context.Background(), packetConn.LocalAddr().String(),
+			NewDNSQuery("example.com", DNSTypeA))
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.EqualError(t, err, "exchanging over UDP: reading response: context deadline exceeded")
+	})
+}
+
+func Test_checkDNSResponse(t *testing.T) {
+	t.Parallel()
+
+	query := NewDNSQuery("example.com", DNSTypeA)
+	query.ID = 1
+	response := &DNSMessage{
+		ID:        1,
+		Response:  true,
+		Questions: []DNSQuestion{{Name: "EXAMPLE.com.", Type: DNSTypeA, Class: DNSClassINET}},
+	}
+	require.NoError(t, checkDNSResponse(query, response))
+
+	response.Questions[0].Type = DNSTypeAAAA
+	err := checkDNSResponse(query, response)
+	assert.ErrorIs(t, err, ErrDNSResponseMismatch)
+	assert.EqualError(t, err, "DNS response does not match query: "+
+		"expected question example.com A and received EXAMPLE.com. AAAA")
+}
+
+func Test_withDefaultPort(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "1.1.1.1:53", withDefaultPort("1.1.1.1", "53"))
+	assert.Equal(t, "1.1.1.1:5353", withDefaultPort("1.1.1.1:5353", "53"))
+	assert.Equal(t, "[2606:4700::1111]:53", withDefaultPort("2606:4700::1111", "53"))
+	assert.Equal(t, "[2606:4700::1111]:53", withDefaultPort("[2606:4700::1111]", "53"))
+}
@@ -0,0 +1,525 @@
+package connectivity
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// DNSType is a DNS resource record type.
+type DNSType uint16
+
+const (
+	DNSTypeA     DNSType = 1
+	DNSTypeNS    DNSType = 2
+	DNSTypeCNAME DNSType = 5
+	DNSTypeSOA   DNSType = 6
+	DNSTypePTR   DNSType = 12
+	DNSTypeMX    DNSType = 15
+	DNSTypeTXT   DNSType = 16
+	DNSTypeAAAA  DNSType = 28
+	DNSTypeSRV   DNSType = 33
+	DNSTypeOPT   DNSType = 41
+	DNSTypeANY   DNSType = 255
+)
+
+func (t DNSType) String() string {
+	switch t {
+	case DNSTypeA:
+		return "A"
+	case DNSTypeNS:
+		return "NS"
+	case DNSTypeCNAME:
+		return "CNAME"
+	case DNSTypeSOA:
+		return "SOA"
+	case DNSTypePTR:
+		return "PTR"
+	case DNSTypeMX:
+		return "MX"
+	case DNSTypeTXT:
+		return "TXT"
+	case DNSTypeAAAA:
+		return "AAAA"
+	case DNSTypeSRV:
+		return "SRV"
+	case DNSTypeOPT:
+		return "OPT"
+	case DNSTypeANY:
+		return "ANY"
+	default:
+		return "TYPE" + strconv.Itoa(int(t))
+	}
+}
+
+// DNSClassINET is the Internet DNS class.
+const DNSClassINET uint16 = 1
+
+// DNSRCode is a DNS response code, including the EDNS0
+// extended response code bits.
+type DNSRCode uint16
+
+const (
+	DNSRCodeNoError  DNSRCode = 0
+	DNSRCodeFormErr  DNSRCode = 1
+	DNSRCodeServFail DNSRCode = 2
+	DNSRCodeNXDomain DNSRCode = 3
+	DNSRCodeNotImp   DNSRCode = 4
+	DNSRCodeRefused  DNSRCode = 5
+	DNSRCodeBadVers  DNSRCode = 16
+)
+
+func (r DNSRCode) String() string {
+	switch r {
+	case DNSRCodeNoError:
+		return "NOERROR"
+	case DNSRCodeFormErr:
+		return "FORMERR"
+	case DNSRCodeServFail:
+		return "SERVFAIL"
+	case DNSRCodeNXDomain:
+		return "NXDOMAIN"
+	case DNSRCodeNotImp:
+		return "NOTIMP"
+	case DNSRCodeRefused:
+		return "REFUSED"
+	case DNSRCodeBadVers:
+		return "BADVERS"
+	default:
+		return "RCODE" + strconv.Itoa(int(r))
+	}
+}
+
+// DNSMessage is a DNS message as defined in RFC 1035,
+// with EDNS0 support as defined in RFC 6891.
+type DNSMessage struct {
+	ID                 uint16
+	Response           bool
+	Opcode             uint8
+	Authoritative      bool
+	Truncated          bool
+	RecursionDesired   bool
+	RecursionAvailable bool
+	RCode              DNSRCode
+	Questions          []DNSQuestion
+	Answers            []DNSRecord
+	Authorities        []DNSRecord
+	// Additionals are the additional records,
+	// excluding the EDNS0 OPT record.
+	Additionals []DNSRecord
+	// EDNS is the EDNS0 OPT record data, and is nil
+	// if the message has no OPT record.
+	EDNS *DNSEDNS
+}
+
+// DNSQuestion is a question of a DNS message.
+type DNSQuestion struct {
+	Name  string
+	Type  DNSType
+	Class uint16
+}
+
+// DNSRecord is a resource record of a DNS message.
+type DNSRecord struct {
+	Name  string
+	Type  DNSType
+	Class uint16
+	TTL   uint32
+	// Data is the record data in wire format, where domain
+	// names are never compressed.
+	Data []byte
+}
+
+// DNSEDNS contains the EDNS0 OPT record fields.
+type DNSEDNS struct {
+	// UDPSize is the maximum UDP payload size of the sender.
+	UDPSize uint16
+	// Version is the EDNS version, and is 0 for EDNS0.
+	Version uint8
+	// DNSSECOK is the DO bit indicating DNSSEC records are accepted.
+	DNSSECOK bool
+	// Options is the raw options data of the OPT record.
+	Options []byte
+}
+
+var (
+	ErrDNSMessageMalformed = errors.New("DNS message is malformed")
+	ErrDNSNameInvalid      = errors.New("DNS name is not valid")
+)
+
+const (
+	dnsHeaderSize     = 12
+	dnsMaxLabelSize   = 63
+	dnsMaxNameSize    = 255
+	dnsMaxCompression = 64
+
+	dnsFlagResponse           = 1 << 15
+	dnsFlagAuthoritative      = 1 << 10
+	dnsFlagTruncated          = 1 << 9
+	dnsFlagRecursionDesired   = 1 << 8
+	dnsFlagRecursionAvailable = 1 << 7
+	dnsOpcodeShift            = 11
+	dnsOpcodeMask             = 0xf
+	dnsRCodeBits              = 4
+	dnsRCodeMask              = 1<<dnsRCodeBits - 1
+
+	// EDNS0 OPT record TTL fields, see RFC 6891 section 6.1.3.
+	dnsEDNSExtendedRCodeShift = 24
+	dnsEDNSVersionShift       = 16
+	dnsEDNSFlagDNSSECOK       = 1 << 15
+)
+
+// Pack encodes the message in the DNS wire format, without
+// name compression.
+func (m *DNSMessage) Pack() (packed []byte, err error) {
+	additionalsCount := len(m.Additionals)
+	if m.EDNS != nil {
+		additionalsCount++
+	}
+
+	flags := uint16(m.Opcode&dnsOpcodeMask)<<dnsOpcodeShift |
+		uint16(m.RCode)&dnsRCodeMask
+	flags |= flagIf(m.Response, dnsFlagResponse)
+	flags |= flagIf(m.Authoritative, dnsFlagAuthoritative)
+	flags |= flagIf(m.Truncated, dnsFlagTruncated)
+	flags |= flagIf(m.RecursionDesired, dnsFlagRecursionDesired)
+	flags |= flagIf(m.RecursionAvailable, dnsFlagRecursionAvailable)
+
+	const minMessageSize = 512
+	packed = make([]byte, dnsHeaderSize, minMessageSize)
+	binary.BigEndian.PutUint16(packed[0:], m.ID)
+	binary.BigEndian.PutUint16(packed[2:], flags)
+	binary.BigEndian.PutUint16(packed[4:], uint16(len(m.Questions)))
+	binary.BigEndian.PutUint16(packed[6:], uint16(len(m.Answers)))
+	binary.BigEndian.PutUint16(packed[8:], uint16(len(m.Authorities)))
+	binary.BigEndian.PutUint16(packed[10:], uint16(additionalsCount))
+
+	for _, question := range m.Questions {
+		packed, err = appendDNSName(packed, question.Name)
+		if err != nil {
+			return nil, fmt.Errorf("packing question: %w", err)
+		}
+		packed = binary.BigEndian.AppendUint16(packed, uint16(question.Type))
+		packed = binary.BigEndian.AppendUint16(packed, question.Class)
+	}
+
+	sections := [][]DNSRecord{m.Answers, m.Authorities, m.Additionals}
+	for _, records := range sections {
+		for _, record := range records {
+			packed, err = appendDNSRecord(packed, record)
+			if err != nil {
+				return nil, fmt.Errorf("packing record: %w", err)
+			}
+		}
+	}
+
+	if m.EDNS != nil {
+		ttl := uint32(m.RCode>>dnsRCodeBits)<<dnsEDNSExtendedRCodeShift |
+			uint32(m.EDNS.Version)<<dnsEDNSVersionShift |
+			uint32(flagIf(m.EDNS.DNSSECOK, dnsEDNSFlagDNSSECOK))
+		packed, err = appendDNSRecord(packed, DNSRecord{
+			Name:  ".",
+			Type:  DNSTypeOPT,
+			Class: m.EDNS.UDPSize,
+			TTL:   ttl,
+			Data:  m.EDNS.Options,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("packing OPT record: %w", err)
+		}
+	}
+
+	return packed, nil
+}
+
+func flagIf(condition bool, flag uint16) uint16 {
+	if condition {
+		return flag
+	}
+	return 0
+}
+
+func appendDNSRecord(packed []byte, record DNSRecord) ([]byte, error) {
+	packed, err := appendDNSName(packed, record.Name)
+	if err != nil {
+		return nil, err
+	}
+	packed = binary.BigEndian.AppendUint16(packed, uint16(record.Type))
+	packed = binary.BigEndian.AppendUint16(packed, record.Class)
+	packed = binary.BigEndian.AppendUint32(packed, record.TTL)
+	packed = binary.BigEndian.AppendUint16(packed, uint16(len(record.Data)))
+	return append(packed, record.Data...), nil
+}
+
+// appendDNSName appends the domain name given in wire format.
+func appendDNSName(packed []byte, name string) ([]byte, error) {
+	name = strings.TrimSuffix(name, ".")
+	if len(name)+2 > dnsMaxNameSize { // leading length and root label
+		return nil, fmt.Errorf("%w: %q exceeds %d bytes", ErrDNSNameInvalid, name, dnsMaxNameSize)
+	}
+
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if label == "" || len(label) > dnsMaxLabelSize {
+				return nil, fmt.Errorf("%w: %q has an empty label or a label exceeding %d bytes",
+					ErrDNSNameInvalid, name, dnsMaxLabelSize)
+			}
+			packed = append(packed, byte(len(label)))
+			packed = append(packed, label...)
+		}
+	}
+	return append(packed, 0), nil
+}
+
+// UnpackDNSMessage decodes a message in the DNS wire format.
+func UnpackDNSMessage(packed []byte) (message *DNSMessage, err error) {
+	if len(packed) < dnsHeaderSize {
+		return nil, fmt.Errorf("%w: %d bytes is smaller than the header size",
+			ErrDNSMessageMalformed, len(packed))
+	}
+
+	flags := binary.BigEndian.Uint16(packed[2:])
+	message = &DNSMessage{
+		ID:                 binary.BigEndian.Uint16(packed[0:]),
+		Response:           flags&dnsFlagResponse != 0,
+		Opcode:             uint8(flags>>dnsOpcodeShift) & dnsOpcodeMask,
+		Authoritative:      flags&dnsFlagAuthoritative != 0,
+		Truncated:          flags&dnsFlagTruncated != 0,
+		RecursionDesired:   flags&dnsFlagRecursionDesired != 0,
+		RecursionAvailable: flags&dnsFlagRecursionAvailable != 0,
+		RCode:              DNSRCode(flags & dnsRCodeMask),
+	}
+	questionsCount := binary.BigEndian.Uint16(packed[4:])
+	recordsCounts := []uint16{
+		binary.BigEndian.Uint16(packed[6:]),
+		binary.BigEndian.Uint16(packed[8:]),
+		binary.BigEndian.Uint16(packed[10:]),
+	}
+
+	offset := dnsHeaderSize
+	for i := 0; i < int(questionsCount); i++ {
+		var question DNSQuestion
+		question.Name, offset, err = readDNSName(packed, offset)
+		if err != nil {
+			return nil, fmt.Errorf("unpacking question %d: %w", i, err)
+		}
+		const questionFieldsSize = 4
+		if offset+questionFieldsSize > len(packed) {
+			return nil, fmt.Errorf("%w: question %d is truncated", ErrDNSMessageMalformed, i)
+		}
+		question.Type = DNSType(binary.BigEndian.Uint16(packed[offset:]))
+		question.Class = binary.BigEndian.Uint16(packed[offset+2:])
+		offset += questionFieldsSize
+		message.Questions = append(message.Questions, question)
+	}
+
+	sections := []*[]DNSRecord{&message.Answers, &message.Authorities, &message.Additionals}
+	for sectionIndex, section := range sections {
+		for i := 0; i < int(recordsCounts[sectionIndex]); i++ {
+			var record DNSRecord
+			record, offset, err = readDNSRecord(packed, offset)
+			if err != nil {
+				return nil, fmt.Errorf("unpacking record: %w", err)
+			}
+
+			if record.Type == DNSTypeOPT && section == &message.Additionals {
+				message.EDNS = &DNSEDNS{
+					UDPSize:  record.Class,
+					Version:  uint8(record.TTL >> dnsEDNSVersionShift),
+					DNSSECOK: record.TTL&dnsEDNSFlagDNSSECOK != 0,
+					Options:  record.Data,
+				}
+				extendedRCode := DNSRCode(record.TTL >> dnsEDNSExtendedRCodeShift)
+				message.RCode |= extendedRCode << dnsRCodeBits
+				continue
+			}
+			*section = append(*section, record)
+		}
+	}
+
+	return message, nil
+}
+
+func readDNSRecord(packed []byte, offset int) (record DNSRecord, newOffset int, err error) {
+	record.Name, offset, err = readDNSName(packed, offset)
+	if err != nil {
+		return record, 0, err
+	}
+
+	const recordFieldsSize = 10
+	if offset+recordFieldsSize > len(packed) {
+		return record, 0, fmt.Errorf("%w: record fields are truncated", ErrDNSMessageMalformed)
+	}
+	record.Type = DNSType(binary.BigEndian.Uint16(packed[offset:]))
+	record.Class = binary.BigEndian.Uint16(packed[offset+2:])
+	record.TTL = binary.BigEndian.Uint32(packed[offset+4:])
+	dataLength := int(binary.BigEndian.Uint16(packed[offset+8:]))
+	offset += recordFieldsSize
+	if offset+dataLength > len(packed) {
+		return record, 0, fmt.Errorf("%w: record data is truncated", ErrDNSMessageMalformed)
+	}
+
+	record.Data, err = decompressDNSData(packed, offset, dataLength, record.Type)
+	if err != nil {
+		return record, 0, fmt.Errorf("decompressing %s record data: %w", record.Type, err)
+	}
+	return record, offset + dataLength, nil
+}
+
+// decompressDNSData returns the record data with its domain names
+// decompressed, for the record types defined in RFC 1035 which
+// can contain compressed domain names.
+func decompressDNSData(packed []byte, offset, length int, recordType DNSType) (
+	data []byte, err error) {
+	end := offset + length
+	var prefixSize, namesCount int
+	switch recordType {
+	case DNSTypeNS, DNSTypeCNAME, DNSTypePTR:
+		namesCount = 1
+	case DNSTypeMX:
+		const preferenceSize = 2
+		prefixSize, namesCount = preferenceSize, 1
+	case DNSTypeSOA:
+		const primaryAndMailbox = 2
+		namesCount = primaryAndMailbox
+	default:
+		return append([]byte(nil), packed[offset:end]...), nil
+	}
+
+	if offset+prefixSize > end {
+		return nil, fmt.Errorf("%w: data is too short", ErrDNSMessageMalformed)
+	}
+	data = append(data, packed[offset:offset+prefixSize]...)
+	offset += prefixSize
+	for i := 0; i < namesCount; i++ {
+		var name string
+		name, offset, err = readDNSName(packed[:end], offset)
+		if err != nil {
+			return nil, err
+		}
+		data, err = appendDNSName(data, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(data, packed[offset:end]...), nil
+}
+
+// readDNSName reads a possibly compressed domain name at the offset
+// given, and returns it in its fully qualified form with a trailing
+// dot, together with the offset right after the name.
+func readDNSName(packed []byte, offset int) (name string, newOffset int, err error) {
+	var labels []string
+	newOffset = -1
+	for hops := 0; ; {
+		if offset >= len(packed) {
+			return "", 0, fmt.Errorf("%w: name is truncated", ErrDNSMessageMalformed)
+		}
+		length := int(packed[offset])
+		const pointerMask = 0xc0
+		switch {
+		case length == 0:
+			if newOffset == -1 {
+				newOffset = offset + 1
+			}
+			return strings.Join(labels, ".") + ".", newOffset, nil
+		case length&pointerMask == pointerMask:
+			if offset+1 >= len(packed) {
+				return "", 0, fmt.Errorf("%w: name pointer is truncated", ErrDNSMessageMalformed)
+			}
+			hops++
+			if hops > dnsMaxCompression {
+				return "", 0, fmt.Errorf("%w: too many name compression pointers", ErrDNSMessageMalformed)
+			}
+			const pointerSize = 2
+			if newOffset == -1 {
+				newOffset = offset + pointerSize
+			}
+			offset = int(binary.BigEndian.Uint16(packed[offset:]) & 0x3fff)
+		case length > dnsMaxLabelSize:
+			return "", 0, fmt.Errorf("%w: label length %d is not valid", ErrDNSMessageMalformed, length)
+		default:
+			offset++
+			if offset+length > len(packed) {
+				return "", 0, fmt.Errorf("%w: label is truncated", ErrDNSMessageMalformed)
+			}
+			labels = append(labels, string(packed[offset:offset+length]))
+			offset += length
+		}
+	}
+}
+
+// Value returns the record data in a human readable presentation
+// format for the A, AAAA, NS, CNAME, PTR, MX, TXT, SRV and SOA types,
+// and as a hexadecimal string for other types.
+func (r DNSRecord) Value() string {
+	switch r.Type {
+	case DNSTypeA, DNSTypeAAAA:
+		address, ok := netip.AddrFromSlice(r.Data)
+		if ok {
+			return address.String()
+		}
+	case DNSTypeNS, DNSTypeCNAME, DNSTypePTR:
+		name, _, err := readDNSName(r.Data, 0)
+		if err == nil {
+			return name
+		}
+	case DNSTypeMX:
+		const preferenceSize = 2
+		if len(r.Data) > preferenceSize {
+			name, _, err := readDNSName(r.Data, preferenceSize)
+			if err == nil {
+				return strconv.Itoa(int(binary.BigEndian.Uint16(r.Data))) + " " + name
+			}
+		}
+	case DNSTypeTXT:
+		value, ok := txtValue(r.Data)
+		if ok {
+			return value
+		}
+	case DNSTypeSRV:
+		const fieldsSize = 6
+		if len(r.Data) > fieldsSize {
+			name, _, err := readDNSName(r.Data, fieldsSize)
+			if err == nil {
+				return fmt.Sprintf("%d %d %d %s", binary.BigEndian.Uint16(r.Data),
+					binary.BigEndian.Uint16(r.Data[2:]), binary.BigEndian.Uint16(r.Data[4:]), name)
+			}
+		}
+	case DNSTypeSOA:
+		primary, offset, err := readDNSName(r.Data, 0)
+		if err != nil {
+			break
+		}
+		mailbox, offset, err := readDNSName(r.Data, offset)
+		const fieldsSize = 20
+		if err == nil && offset+fieldsSize == len(r.Data) {
+			return fmt.Sprintf("%s %s %d", primary, mailbox, binary.BigEndian.Uint32(r.Data[offset:]))
+		}
+	}
+	return hex.EncodeToString(r.Data)
+}
+
+// txtValue returns the character strings of TXT record data
+// quoted and separated by spaces.
+func txtValue(data []byte) (value string, ok bool) {
+	var values []string
+	for offset := 0; offset < len(data); {
+		length := int(data[offset])
+		offset++
+		if offset+length > len(data) {
+			return "", false
+		}
+		values = append(values, strconv.Quote(string(data[offset:offset+length])))
+		offset += length
+	}
+	return strings.Join(values, " "), true
+}
+
+func (r DNSRecord) String() string {
+	return fmt.Sprintf("%s %d %s %s", r.Name, r.TTL, r.Type, r.Value())
+}
@@ -0,0 +1,176 @@
+package connectivity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DNSMessage_PackUnpack(t *testing.T) {
+	t.Parallel()
+
+	message := &DNSMessage{
+		ID:                 0xbeef,
+		Response:           true,
+		Opcode:             2,
+		Authoritative:      true,
+		RecursionDesired:   true,
+		RecursionAvailable: true,
+		RCode:              DNSRCodeBadVers,
+		Questions: []DNSQuestion{
+			{Name: "example.com.", Type: DNSTypeMX, Class: DNSClassINET},
+		},
+		Answers: []DNSRecord{
+			{Name: "example.com.", Type: DNSTypeMX, Class: DNSClassINET, TTL: 300,
+				Data: []byte{0, 10, 4, 'm', 'a', 'i', 'l', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0}},
+		},
+		Authorities: []DNSRecord{
+			{Name: "example.com.", Type: DNSTypeNS, Class: DNSClassINET, TTL: 60,
+				Data: []byte{2, 'n', 's', 0}},
+		},
+		Additionals: []DNSRecord{
+			{Name: "mail.example.com.", Type: DNSTypeA, Class: DNSClassINET, TTL: 60,
+				Data: []byte{1, 2, 3, 4}},
+		},
+		EDNS: &DNSEDNS{UDPSize: 4096, DNSSECOK: true, Options: []byte{0, 10, 0, 0}},
+	}
+
+	packed, err := message.Pack()
+	require.NoError(t, err)
+
+	unpacked, err := UnpackDNSMessage(packed)
+	require.NoError(t, err)
+	assert.Equal(t, message, unpacked)
+	assert.Equal(t, "example.com. 300 MX 10 mail.example.com.", unpacked.Answers[0].String())
+	assert.Equal(t, "1.2.3.4", unpacked.Additionals[0].Value())
+}
+
+func Test_UnpackDNSMessage(t *testing.T) {
+	t.Parallel()
+
+	header := []byte{0x12, 0x34, 0x81, 0x83, 0, 1, 0, 2, 0, 0, 0, 0}
+	question := []byte{3, 'w', 'w', 'w', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, 0, 5, 0, 1}
+
+	testCases := map[string]struct {
+		packed     []byte
+		message    *DNSMessage
+		errWrapped error
+		errMessage string
+	}{
+		"compressed names": {
+			packed: concat(header, question,
+				// www.example.com. CNAME target.example.com. with compression
+				[]byte{0xc0, 12, 0, 5, 0, 1, 0, 0, 0, 60, 0, 9, 6, 't', 'a', 'r', 'g', 'e', 't', 0xc0, 16},
+				// target.example.com. TXT "hello" "world"
+				[]byte{0xc0, 45, 0, 16, 0, 1, 0, 0, 0, 60, 0, 12, 5, 'h', 'e', 'l', 'l', 'o', 5, 'w', 'o', 'r', 'l', 'd'},
+			),
+			message: &DNSMessage{
+				ID:                 0x1234,
+				Response:           true,
+				RecursionDesired:   true,
+				RecursionAvailable: true,
+				RCode:              DNSRCodeNXDomain,
+				Questions: []DNSQuestion{
+					{Name: "www.example.com.", Type: DNSTypeCNAME, Class: DNSClassINET},
+				},
+				Answers: []DNSRecord{
+					{Name: "www.example.com.", Type: DNSTypeCNAME, Class: DNSClassINET, TTL: 60,
+						Data: []byte{6, 't', 'a', 'r', 'g', 'e', 't', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0}},
+					{Name: "target.example.com.", Type: DNSTypeTXT, Class: DNSClassINET, TTL: 60,
+						Data: []byte{5, 'h', 'e', 'l', 'l', 'o', 5, 'w', 'o', 'r', 'l', 'd'}},
+				},
+			},
+		},
+		"header too short": {
+			packed:     header[:11],
+			errWrapped: ErrDNSMessageMalformed,
+			errMessage: "DNS message is malformed: 11 bytes is smaller than the header size",
+		},
+		"question truncated": {
+			packed:     concat(header, question[:18]),
+			errWrapped: ErrDNSMessageMalformed,
+			errMessage: "DNS message is malformed: question 0 is truncated",
+		},
+		"compression loop": {
+			packed:     concat(header, []byte{0xc0, 12}),
+			errWrapped: ErrDNSMessageMalformed,
+			errMessage: "unpacking question 0: DNS message is malformed: too many name compression pointers",
+		},
+		"record data truncated": {
+			packed:     concat(header, question, []byte{0xc0, 12, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4, 1, 2}),
+			errWrapped: ErrDNSMessageMalformed,
+			errMessage: "unpacking record: DNS message is malformed: record data is truncated",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			message, err := UnpackDNSMessage(testCase.packed)
+
+			assert.Equal(t, testCase.message, message)
+			if testCase.errWrapped == nil {
+				require.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			assert.EqualError(t, err, testCase.errMessage)
+		})
+	}
+}
+
+func Test_DNSRecord_Value(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		record DNSRecord
+		value  string
+	}{
+		"AAAA": {
+			record: DNSRecord{Type: DNSTypeAAAA, Data: []byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}},
+			value:  "2001:db8::1",
+		},
+		"TXT": {
+			record: DNSRecord{Type: DNSTypeTXT, Data: []byte{2, 'a', '"', 0}},
+			value:  `"a\"" ""`,
+		},
+		"SRV": {
+			record: DNSRecord{Type: DNSTypeSRV, Data: []byte{0, 1, 0, 2, 0x13, 0xc4, 3, 's', 'i', 'p', 0}},
+			value:  "1 2 5060 sip.",
+		},
+		"unknown": {
+			record: DNSRecord{Type: 99, Data: []byte{0xab, 0xcd}},
+			value:  "abcd",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, testCase.value, testCase.record.Value())
+		})
+	}
+}
+
+func Test_DNSMessage_Pack_invalidName(t *testing.T) {
+	t.Parallel()
+
+	message := NewDNSQuery("a..b", DNSTypeA)
+
+	_, err := message.Pack()
+
+	assert.ErrorIs(t, err, ErrDNSNameInvalid)
+	assert.EqualError(t, err, `packing question: DNS name is not valid: "a..b" `+
+		`has an empty label or a label exceeding 63 bytes`)
+}
+
+func concat(slices ...[]byte) (result []byte) {
+	for _, slice := range slices {
+		result = append(result, slice...)
+	}
+	return result
+}
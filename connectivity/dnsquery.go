@@ -0,0 +1,110 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DNSQueryResult is the result of a DNS query sent to a server.
+type DNSQueryResult struct {
+	// RCode is the response code of the response.
+	RCode DNSRCode
+	// Answers are the answer records of the response.
+	Answers []DNSRecord
+	// Latency is the duration of the exchange with the server.
+	Latency time.Duration
+	// Response is the full response message.
+	Response *DNSMessage
+}
+
+// NewDNSQueryChecker creates a new DNS query checker sending the
+// question given with the exchanger given, and expecting the
+// response code given.
+func NewDNSQueryChecker(exchanger DNSExchanger, question DNSQuestion,
+	expectedRCode DNSRCode) *DNSQueryChecker {
+	return &DNSQueryChecker{
+		exchanger:     exchanger,
+		question:      question,
+		expectedRCode: expectedRCode,
+	}
+}
+
+// DNSQueryChecker implements a checker to query specific DNS servers
+// and verify the response code of their responses.
+type DNSQueryChecker struct {
+	exchanger     DNSExchanger
+	question      DNSQuestion
+	expectedRCode DNSRCode
+	// answersRequired is true to require at least one answer of the
+	// question type if the expected response code is NOERROR.
+	answersRequired bool
+}
+
+// ParallelChecks verifies each of the DNS servers given responds to
+// the question with the expected response code.
+// It returns a slice of errors with the same indexing and order as the
+// urls, meaning that some errors might be nil or not. You should ensure
+// to iterate over the errors and check each of them.
+func (c *DNSQueryChecker) ParallelChecks(ctx context.Context, urls []string) (errs []error) {
+	return parallelChecks(ctx, c, urls)
+}
+
+var (
+	ErrDNSRCodeUnexpected = errors.New("unexpected DNS response code received")
+	ErrDNSAnswersMissing  = errors.New("DNS answers are missing")
+)
+
+// Check verifies the DNS server given responds to the question
+// with the expected response code. For checkers created with
+// NewDoHChecker or NewDoTChecker, if the expected response code is
+// NOERROR, the response must also contain at least one answer of the
+// question type, unless the question type is ANY.
+func (c *DNSQueryChecker) Check(ctx context.Context, url string) error {
+	result, err := c.Query(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	if result.RCode != c.expectedRCode {
+		return fmt.Errorf("%w: expected %s and received %s for %s %s",
+			ErrDNSRCodeUnexpected, c.expectedRCode, result.RCode,
+			c.question.Name, c.question.Type)
+	}
+
+	if !c.answersRequired || c.expectedRCode != DNSRCodeNoError ||
+		c.question.Type == DNSTypeANY {
+		return nil
+	}
+	for _, answer := range result.Answers {
+		if answer.Type == c.question.Type {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no %s record in %d answer(s) for %s",
+		ErrDNSAnswersMissing, c.question.Type, len(result.Answers), c.question.Name)
+}
+
+// Query sends the question to the DNS server given and returns
+// the response code, answers and latency of the response.
+func (c *DNSQueryChecker) Query(ctx context.Context, server string) (
+	result DNSQueryResult, err error) {
+	query := NewDNSQuery(c.question.Name, c.question.Type)
+	if c.question.Class != 0 {
+		query.Questions[0].Class = c.question.Class
+	}
+
+	start := time.Now()
+	response, err := c.exchanger.Exchange(ctx, server, query)
+	if err != nil {
+		return result, fmt.Errorf("querying %s: %w", server, err)
+	}
+
+	return DNSQueryResult{
+		RCode:    response.RCode,
+		Answers:  response.Answers,
+		Latency:  time.Since(start),
+		Response: response,
+	}, nil
+}
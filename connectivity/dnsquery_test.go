@@ -0,0 +1,64 @@
+package connectivity
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DNSQueryChecker(t *testing.T) {
+	t.Parallel()
+
+	standIn := newDNSStandIn(t, func(query *DNSMessage) *DNSMessage {
+		if query.Questions[0].Name != "example.com." {
+			return &DNSMessage{RCode: DNSRCodeNXDomain}
+		}
+		return answerA(1)(query)
+	})
+	client := NewDNSClient(&net.Dialer{}, time.Second)
+
+	t.Run("query", func(t *testing.T) {
+		t.Parallel()
+
+		checker := NewDNSQueryChecker(client,
+			DNSQuestion{Name: "example.com", Type: DNSTypeA}, DNSRCodeNoError)
+
+		result, err := checker.Query(context.Background(), standIn.address)
+
+		require.NoError(t, err)
+		assert.Equal(t, DNSRCodeNoError, result.RCode)
+		require.Len(t, result.Answers, 1)
+		assert.Equal(t, "example.com. 60 A 10.0.0.0", result.Answers[0].String())
+		assert.Positive(t, result.Latency)
+	})
+
+	t.Run("answers not required", func(t *testing.T) {
+		t.Parallel()
+
+		checker := NewDNSQueryChecker(client,
+			DNSQuestion{Name: "example.com", Type: DNSTypeAAAA}, DNSRCodeNoError)
+
+		err := checker.Check(context.Background(), standIn.address)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("parallel checks", func(t *testing.T) {
+		t.Parallel()
+
+		checker := NewDNSQueryChecker(client,
+			DNSQuestion{Name: "unknown.example", Type: DNSTypeA}, DNSRCodeNoError)
+
+		errs := checker.ParallelChecks(context.Background(), []string{standIn.address, "127.0.0.1:0"})
+
+		require.Len(t, errs, 2)
+		assert.ErrorIs(t, errs[0], ErrDNSRCodeUnexpected)
+		assert.EqualError(t, errs[0], "unexpected DNS response code received: "+
+			"expected NOERROR and received NXDOMAIN for unknown.example A")
+		assert.Error(t, errs[1])
+	})
+}
@@ -0,0 +1,147 @@
+package connectivity
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	urlpkg "net/url"
+	"strings"
+)
+
+// NewDoHChecker creates a new DNS query checker sending the question
+// given over DNS-over-HTTPS with the HTTP client and method given,
+// and expecting the response code given. If the response code expected
+// is NOERROR, the response must also contain at least one answer of the
+// question type, unless the question type is ANY.
+func NewDoHChecker(client *http.Client, method string, question DNSQuestion,
+	expectedRCode DNSRCode) *DNSQueryChecker {
+	checker := NewDNSQueryChecker(NewDoHClient(client, method), question, expectedRCode)
+	checker.answersRequired = true
+	return checker
+}
+
+// NewDoHClient creates a new DNS-over-HTTPS client as defined in
+// RFC 8484, sending queries with the HTTP method given which can be
+// http.MethodGet or http.MethodPost.
+func NewDoHClient(client *http.Client, method string) *DoHClient {
+	return &DoHClient{
+		client: client,
+		method: method,
+	}
+}
+
+// DoHClient is a DNS-over-HTTPS client.
+type DoHClient struct {
+	client *http.Client
+	method string
+}
+
+var (
+	ErrDoHMethodNotSupported    = errors.New("DNS-over-HTTPS method is not supported")
+	ErrDoHContentTypeUnexpected = errors.New("unexpected DNS-over-HTTPS content type received")
+	ErrDoHResponseBodyTooLarge  = errors.New("DNS-over-HTTPS response body is too large")
+)
+
+const dnsMessageContentType = "application/dns-message"
+
+// Exchange sends the query to the DNS-over-HTTPS server given as an
+// url such as https://dns.example.com/dns-query, or as a host in
+// which case the https scheme and /dns-query path are used.
+// As recommended by RFC 8484, the query is sent with an ID of 0.
+func (c *DoHClient) Exchange(ctx context.Context, server string,
+	query *DNSMessage) (response *DNSMessage, err error) {
+	dohQuery := *query
+	dohQuery.ID = 0
+	packedQuery, err := dohQuery.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("packing query: %w", err)
+	}
+
+	request, err := newDoHRequest(ctx, c.method, dohURL(server), packedQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResponse, err := c.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("doing request: %w", err)
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, &HTTPStatusError{
+			Expected: http.StatusOK,
+			Received: httpResponse.StatusCode,
+			Status:   httpResponse.Status,
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(httpResponse.Header.Get("Content-Type"))
+	if mediaType != dnsMessageContentType {
+		return nil, fmt.Errorf("%w: expected %s and received %q",
+			ErrDoHContentTypeUnexpected, dnsMessageContentType,
+			httpResponse.Header.Get("Content-Type"))
+	}
+
+	const maxMessageSize = 65535
+	packedResponse, err := io.ReadAll(io.LimitReader(httpResponse.Body, maxMessageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	} else if len(packedResponse) > maxMessageSize {
+		return nil, fmt.Errorf("%w: exceeds %d bytes",
+			ErrDoHResponseBodyTooLarge, maxMessageSize)
+	}
+
+	response, err = UnpackDNSMessage(packedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("unpacking response: %w", err)
+	}
+
+	err = checkDNSResponse(&dohQuery, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func dohURL(server string) string {
+	if strings.Contains(server, "://") {
+		return server
+	}
+	return "https://" + server + "/dns-query"
+}
+
+func newDoHRequest(ctx context.Context, method, url string,
+	packedQuery []byte) (request *http.Request, err error) {
+	switch method {
+	case http.MethodGet:
+		u, err := urlpkg.Parse(url)
+		if err != nil {
+			return nil, fmt.Errorf("parsing url: %w", err)
+		}
+		values := u.Query()
+		values.Set("dns", base64.RawURLEncoding.EncodeToString(packedQuery))
+		u.RawQuery = values.Encode()
+		request, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+	case http.MethodPost:
+		request, err = http.NewRequestWithContext(ctx, http.MethodPost, url,
+			bytes.NewReader(packedQuery))
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		request.Header.Set("Content-Type", dnsMessageContentType)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrDoHMethodNotSupported, method)
+	}
+
+	request.Header.Set("Accept", dnsMessageContentType)
+	return request, nil
+}
@@ -0,0 +1,136 @@
+package connectivity
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDoHStandIn starts a local DNS-over-HTTPS server answering
+// queries on the /dns-query path with the handler given.
+func newDoHStandIn(t *testing.T, handler func(query *DNSMessage) *DNSMessage) *httptest.Server {
+	t.Helper()
+
+	standIn := &dnsStandIn{handler: handler}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns-query" || r.Header.Get("Accept") != dnsMessageContentType {
+			http.NotFound(w, r)
+			return
+		}
+
+		var packedQuery []byte
+		var err error
+		switch r.Method {
+		case http.MethodGet:
+			packedQuery, err = base64.RawURLEncoding.DecodeString(r.URL.Query().Get("dns"))
+		case http.MethodPost:
+			if r.Header.Get("Content-Type") != dnsMessageContentType {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			packedQuery, err = io.ReadAll(r.Body)
+		}
+		if err != nil || len(packedQuery) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", dnsMessageContentType)
+		_, _ = w.Write(standIn.respond(packedQuery, 0))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_DoHClient_Exchange(t *testing.T) {
+	t.Parallel()
+
+	server := newDoHStandIn(t, answerA(1))
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	testCases := map[string]struct {
+		method     string
+		server     string
+		errWrapped error
+		errMessage string
+	}{
+		"get": {
+			method: http.MethodGet,
+			server: server.URL + "/dns-query",
+		},
+		"post with host only": {
+			method: http.MethodPost,
+			server: host,
+		},
+		"bad path": {
+			method:     http.MethodGet,
+			server:     server.URL + "/resolve",
+			errWrapped: ErrHTTPStatusUnexpected,
+			errMessage: "unexpected HTTP status received: expected 200 and received 404 Not Found",
+		},
+		"bad method": {
+			method:     http.MethodPut,
+			server:     host,
+			errWrapped: ErrDoHMethodNotSupported,
+			errMessage: "DNS-over-HTTPS method is not supported: PUT",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := NewDoHClient(server.Client(), testCase.method)
+
+			response, err := client.Exchange(context.Background(), testCase.server,
+				NewDNSQuery("example.com", DNSTypeA))
+
+			if testCase.errWrapped != nil {
+				assert.ErrorIs(t, err, testCase.errWrapped)
+				assert.EqualError(t, err, testCase.errMessage)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, uint16(0), response.ID)
+			require.Len(t, response.Answers, 1)
+			assert.Equal(t, "10.0.0.0", response.Answers[0].Value())
+		})
+	}
+}
+
+func Test_DoHChecker(t *testing.T) {
+	t.Parallel()
+
+	server := newDoHStandIn(t, func(*DNSMessage) *DNSMessage {
+		return &DNSMessage{RCode: DNSRCodeServFail}
+	})
+	checker := NewDoHChecker(server.Client(), http.MethodPost,
+		DNSQuestion{Name: "example.com", Type: DNSTypeA}, DNSRCodeNoError)
+
+	err := checker.Check(context.Background(), server.URL+"/dns-query")
+
+	assert.ErrorIs(t, err, ErrDNSRCodeUnexpected)
+	assert.EqualError(t, err, "unexpected DNS response code received: "+
+		"expected NOERROR and received SERVFAIL for example.com A")
+}
+
+func Test_DoHChecker_answersMissing(t *testing.T) {
+	t.Parallel()
+
+	server := newDoHStandIn(t, answerA(1))
+	checker := NewDoHChecker(server.Client(), http.MethodPost,
+		DNSQuestion{Name: "example.com", Type: DNSTypeAAAA}, DNSRCodeNoError)
+
+	err := checker.Check(context.Background(), server.URL+"/dns-query")
+
+	assert.ErrorIs(t, err, ErrDNSAnswersMissing)
+	assert.EqualError(t, err, "DNS answers are missing: no AAAA record in 1 answer(s) for example.com")
+}
@@ -0,0 +1,87 @@
+package connectivity
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+)
+
+// NewDoTChecker creates a new DNS query checker sending the question
+// given over DNS-over-TLS with the dialer, TLS configuration and
+// timeout given, and expecting the response code given. The response
+// answers are verified as described on NewDoHChecker.
+func NewDoTChecker(dialer *net.Dialer, tlsConfig *tls.Config, timeout time.Duration,
+	question DNSQuestion, expectedRCode DNSRCode) *DNSQueryChecker {
+	checker := NewDNSQueryChecker(NewDoTClient(dialer, tlsConfig, timeout), question, expectedRCode)
+	checker.answersRequired = true
+	return checker
+}
+
+// NewDoTClient creates a new DNS-over-TLS client as defined in
+// RFC 7858. The TLS configuration can be nil to use the default
+// configuration, and its server name defaults to the host of the
+// server queried. The timeout given applies to each exchange, and
+// a zero timeout means no timeout other than the context deadline.
+func NewDoTClient(dialer *net.Dialer, tlsConfig *tls.Config, timeout time.Duration) *DoTClient {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return &DoTClient{
+		dialer:    dialer,
+		tlsConfig: tlsConfig,
+		timeout:   timeout,
+	}
+}
+
+// DoTClient is a DNS-over-TLS client.
+type DoTClient struct {
+	dialer    *net.Dialer
+	tlsConfig *tls.Config
+	timeout   time.Duration
+}
+
+// Exchange sends the query to the DNS-over-TLS server given as
+// host:port, or as host in which case port 853 is used.
+func (c *DoTClient) Exchange(ctx context.Context, server string,
+	query *DNSMessage) (response *DNSMessage, err error) {
+	packedQuery, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("packing query: %w", err)
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	address := withDefaultPort(server, "853")
+	tlsConfig := c.tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName, _, _ = net.SplitHostPort(address)
+	}
+	dialer := &tls.Dialer{
+		NetDialer: c.dialer,
+		Config:    tlsConfig,
+	}
+	connection, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("dialing: %w", err)
+	}
+	defer connection.Close()
+	stop := closeOnDone(ctx, connection)
+	defer stop()
+
+	response, err = exchangeStream(connection, packedQuery)
+	if err != nil {
+		return nil, contextOrError(ctx, err)
+	}
+
+	err = checkDNSResponse(query, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,87 @@
+package connectivity
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDoTStandIn starts a local DNS-over-TLS server answering queries
+// with the handler given, and returns its address and the root
+// certificates pool to trust it.
+func newDoTStandIn(t *testing.T, handler func(query *DNSMessage) *DNSMessage) (
+	address string, rootCAs *x509.CertPool) {
+	t.Helper()
+
+	// Borrow the certificate of an httptest TLS server.
+	httpServer := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(httpServer.Close)
+	rootCAs = x509.NewCertPool()
+	rootCAs.AddCert(httpServer.Certificate())
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", httpServer.TLS.Clone())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	standIn := &dnsStandIn{handler: handler}
+	go standIn.serveTCP(listener)
+
+	return listener.Addr().String(), rootCAs
+}
+
+func Test_DoTClient_Exchange(t *testing.T) {
+	t.Parallel()
+
+	address, rootCAs := newDoTStandIn(t, answerA(3))
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewDoTClient(&net.Dialer{}, &tls.Config{
+			RootCAs:    rootCAs,
+			MinVersion: tls.VersionTLS12,
+		}, time.Second)
+
+		response, err := client.Exchange(context.Background(), address,
+			NewDNSQuery("example.com", DNSTypeA))
+
+		require.NoError(t, err)
+		assert.Len(t, response.Answers, 3)
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		t.Parallel()
+
+		client := NewDoTClient(&net.Dialer{}, nil, time.Second)
+
+		_, err := client.Exchange(context.Background(), address,
+			NewDNSQuery("example.com", DNSTypeA))
+
+		var verificationErr *tls.CertificateVerificationError
+		assert.ErrorAs(t, err, &verificationErr)
+	})
+
+	t.Run("checker", func(t *testing.T) {
+		t.Parallel()
+
+		checker := NewDoTChecker(&net.Dialer{}, &tls.Config{
+			RootCAs:    rootCAs,
+			ServerName: "example.com",
+			MinVersion: tls.VersionTLS12,
+		}, time.Second, DNSQuestion{Name: "example.com", Type: DNSTypeA}, DNSRCodeNoError)
+
+		err := checker.Check(context.Background(), address)
+
+		assert.NoError(t, err)
+	})
+}
@@ -37,9 +37,18 @@ func (c *HTTPGetChecker) ParallelChecks(ctx context.Context, urls []string) (err
 // HTTP status code when sending an HTTP GET request to the url over
 // plaintext HTTP.
 func (c *HTTPGetChecker) Check(ctx context.Context, url string) error {
+	_, err := c.CheckTimings(ctx, url)
+	return err
+}
+
+// CheckTimings verifies the HTTP response status code matches the
+// expected HTTP status code when sending an HTTP GET request to the
+// url over plaintext HTTP, and returns the timings of the request.
+func (c *HTTPGetChecker) CheckTimings(ctx context.Context, url string) (
+	timings Timings, err error) {
 	u, err := urlpkg.Parse(url)
 	if err != nil {
-		return fmt.Errorf("parsing url: %w", err)
+		return timings, fmt.Errorf("parsing url: %w", err)
 	}
 
 	u.Scheme = "http"
@@ -48,23 +57,49 @@ func (c *HTTPGetChecker) Check(ctx context.Context, url string) error {
 
 var ErrHTTPStatusUnexpected = errors.New("unexpected HTTP status received")
 
+// HTTPStatusError is the error returned when the HTTP status received
+// is not the one expected. It wraps ErrHTTPStatusUnexpected.
+type HTTPStatusError struct {
+	// Expected is the HTTP status code expected.
+	Expected int
+	// Received is the HTTP status code received.
+	Received int
+	// Status is the HTTP status received, such as "404 Not Found".
+	Status string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s: expected %d and received %s",
+		ErrHTTPStatusUnexpected, e.Expected, e.Status)
+}
+
+func (e *HTTPStatusError) Unwrap() error {
+	return ErrHTTPStatusUnexpected
+}
+
 func httpGetCheck(ctx context.Context, client *http.Client,
-	url string, expectedStatus int) error {
+	url string, expectedStatus int) (timings Timings, err error) {
+	tracer := newTimingsTracer()
+	ctx = tracer.withClientTrace(ctx)
 	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return timings, fmt.Errorf("creating request: %w", err)
 	}
 
 	response, err := client.Do(request)
 	if err != nil {
-		return fmt.Errorf("doing request: %w", err)
+		return tracer.getTimings(), fmt.Errorf("doing request: %w", err)
 	}
 	defer response.Body.Close()
+	timings = tracer.getTimings()
 
 	if response.StatusCode != expectedStatus {
-		return fmt.Errorf("%w: expected %d and received %s",
-			ErrHTTPStatusUnexpected, expectedStatus, response.Status)
+		return timings, &HTTPStatusError{
+			Expected: expectedStatus,
+			Received: response.StatusCode,
+			Status:   response.Status,
+		}
 	}
 
-	return nil
+	return timings, nil
 }
@@ -35,9 +35,18 @@ func (c *HTTPSGetChecker) ParallelChecks(ctx context.Context, urls []string) (er
 // Check verifies the HTTP response status code matches the expected
 // HTTP status code when sending an HTTP GET request to the url over HTTPS.
 func (c *HTTPSGetChecker) Check(ctx context.Context, url string) error {
+	_, err := c.CheckTimings(ctx, url)
+	return err
+}
+
+// CheckTimings verifies the HTTP response status code matches the
+// expected HTTP status code when sending an HTTP GET request to the
+// url over HTTPS, and returns the timings of the request.
+func (c *HTTPSGetChecker) CheckTimings(ctx context.Context, url string) (
+	timings Timings, err error) {
 	u, err := urlpkg.Parse(url)
 	if err != nil {
-		return fmt.Errorf("parsing url: %w", err)
+		return timings, fmt.Errorf("parsing url: %w", err)
 	}
 
 	u.Scheme = "https"
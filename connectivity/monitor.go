@@ -0,0 +1,295 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State is the state of a monitored target.
+type State uint8
+
+const (
+	// StateUnknown is the state of a target before enough
+	// consecutive checks decided its state.
+	StateUnknown State = iota
+	// StateUp is the state of a reachable target.
+	StateUp
+	// StateDown is the state of an unreachable target.
+	StateDown
+)
+
+func (s State) String() string {
+	switch s {
+	case StateUnknown:
+		return "unknown"
+	case StateUp:
+		return "up"
+	case StateDown:
+		return "down"
+	default:
+		return "invalid"
+	}
+}
+
+// MonitorTarget is a target to monitor.
+type MonitorTarget struct {
+	// URL is the url to check.
+	URL string
+	// Checker is the checker to check the url with.
+	Checker SingleChecker
+	// Interval is the interval between two checks of the target,
+	// and defaults to the interval of the monitor settings.
+	Interval time.Duration
+}
+
+// TargetState is the state of a monitored target.
+type TargetState struct {
+	// URL is the url of the target.
+	URL string
+	// State is the current state of the target.
+	State State
+	// ConsecutiveFailures is the number of consecutive failed checks.
+	ConsecutiveFailures int
+	// ConsecutiveSuccesses is the number of consecutive successful checks.
+	ConsecutiveSuccesses int
+	// LastErr is the error of the last check, and is nil
+	// if it succeeded.
+	LastErr error
+	// LastCheck is the time of the last check, and is the
+	// zero time if the target was not checked yet.
+	LastCheck time.Time
+	// LastChange is the time of the last state change, and is
+	// the zero time if the state never changed.
+	LastChange time.Time
+}
+
+// Transition is a state change of a monitored target.
+type Transition struct {
+	// URL is the url of the target.
+	URL string
+	// From is the previous state of the target.
+	From State
+	// To is the new state of the target.
+	To State
+	// Err is the error of the check causing the transition,
+	// and is nil for a transition to StateUp.
+	Err error
+	// Time is the time of the transition.
+	Time time.Time
+}
+
+// MonitorSettings are the settings for a Monitor.
+type MonitorSettings struct {
+	// Interval is the default interval between two checks of
+	// a target, and defaults to 30 seconds.
+	Interval time.Duration
+	// CheckTimeout is the timeout for each check, and defaults
+	// to zero which means no timeout other than the context.
+	CheckTimeout time.Duration
+	// FailureThreshold is the number of consecutive failed checks
+	// for a target to be down, and defaults to 3.
+	FailureThreshold int
+	// SuccessThreshold is the number of consecutive successful
+	// checks for a target to be up, and defaults to 2.
+	SuccessThreshold int
+	// OnTransition is called on each state transition of a target.
+	// It can be called concurrently for different targets, and
+	// defaults to nil.
+	OnTransition func(transition Transition)
+	// Transitions is a channel where each state transition of a
+	// target is sent. The monitor blocks on sending to it, so it must
+	// be read from or be buffered. It defaults to nil to not send
+	// transitions.
+	Transitions chan<- Transition
+}
+
+func (s *MonitorSettings) setDefaults() {
+	if s.Interval == 0 {
+		const defaultInterval = 30 * time.Second
+		s.Interval = defaultInterval
+	}
+	if s.FailureThreshold == 0 {
+		const defaultFailureThreshold = 3
+		s.FailureThreshold = defaultFailureThreshold
+	}
+	if s.SuccessThreshold == 0 {
+		const defaultSuccessThreshold = 2
+		s.SuccessThreshold = defaultSuccessThreshold
+	}
+}
+
+var ErrMonitorSettingsInvalid = errors.New("monitor settings are not valid")
+
+func (s *MonitorSettings) validate() (err error) {
+	switch {
+	case s.Interval < 0:
+		return fmt.Errorf("%w: interval %s cannot be negative",
+			ErrMonitorSettingsInvalid, s.Interval)
+	case s.CheckTimeout < 0:
+		return fmt.Errorf("%w: check timeout %s cannot be negative",
+			ErrMonitorSettingsInvalid, s.CheckTimeout)
+	case s.FailureThreshold < 0:
+		return fmt.Errorf("%w: failure threshold %d cannot be negative",
+			ErrMonitorSettingsInvalid, s.FailureThreshold)
+	case s.SuccessThreshold < 0:
+		return fmt.Errorf("%w: success threshold %d cannot be negative",
+			ErrMonitorSettingsInvalid, s.SuccessThreshold)
+	}
+	return nil
+}
+
+// Monitor checks targets periodically and tracks their up or down
+// state, requiring consecutive failures or successes to change
+// state to suppress flapping.
+type Monitor struct {
+	targets  []MonitorTarget
+	settings MonitorSettings
+	mutex    sync.RWMutex
+	states   []TargetState
+}
+
+// NewMonitor creates a new monitor for the targets given.
+// It returns an error wrapping ErrMonitorSettingsInvalid if a
+// setting or a target interval is negative.
+func NewMonitor(targets []MonitorTarget, settings MonitorSettings) (
+	monitor *Monitor, err error) {
+	settings.setDefaults()
+	err = settings.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]TargetState, len(targets))
+	for i, target := range targets {
+		if target.Interval < 0 {
+			return nil, fmt.Errorf("%w: interval %s of target %s cannot be negative",
+				ErrMonitorSettingsInvalid, target.Interval, target.URL)
+		}
+		states[i].URL = target.URL
+	}
+	return &Monitor{
+		targets:  targets,
+		settings: settings,
+		states:   states,
+	}, nil
+}
+
+// Run checks each target right away and then periodically at its
+// interval, until the context is canceled.
+func (m *Monitor) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := range m.targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m.runTarget(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// Snapshot returns the current state of each target, with the
+// same indexing and order as the targets given to NewMonitor.
+func (m *Monitor) Snapshot() (states []TargetState) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	states = make([]TargetState, len(m.states))
+	copy(states, m.states)
+	return states
+}
+
+func (m *Monitor) runTarget(ctx context.Context, index int) {
+	target := m.targets[index]
+	interval := target.Interval
+	if interval == 0 {
+		interval = m.settings.Interval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := m.check(ctx, target)
+		if ctx.Err() != nil {
+			// Ignore the check result since it likely
+			// failed because the context is canceled.
+			return
+		}
+		m.record(ctx, index, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Monitor) check(ctx context.Context, target MonitorTarget) error {
+	if m.settings.CheckTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.settings.CheckTimeout)
+		defer cancel()
+	}
+	return target.Checker.Check(ctx, target.URL)
+}
+
+func (m *Monitor) record(ctx context.Context, index int, err error) {
+	m.mutex.Lock()
+	transition, changed := m.states[index].update(err, time.Now(),
+		m.settings.FailureThreshold, m.settings.SuccessThreshold)
+	m.mutex.Unlock()
+	if !changed {
+		return
+	}
+
+	if m.settings.OnTransition != nil {
+		m.settings.OnTransition(transition)
+	}
+	if m.settings.Transitions != nil {
+		select {
+		case m.settings.Transitions <- transition:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// update updates the state with the result of a check, and returns
+// the transition and true if the state changed.
+func (s *TargetState) update(err error, now time.Time,
+	failureThreshold, successThreshold int) (transition Transition, changed bool) {
+	s.LastErr = err
+	s.LastCheck = now
+
+	newState := s.State
+	if err != nil {
+		s.ConsecutiveFailures++
+		s.ConsecutiveSuccesses = 0
+		if s.ConsecutiveFailures >= failureThreshold {
+			newState = StateDown
+		}
+	} else {
+		s.ConsecutiveSuccesses++
+		s.ConsecutiveFailures = 0
+		if s.ConsecutiveSuccesses >= successThreshold {
+			newState = StateUp
+		}
+	}
+
+	if newState == s.State {
+		return transition, false
+	}
+
+	transition = Transition{
+		URL:  s.URL,
+		From: s.State,
+		To:   newState,
+		Err:  err,
+		Time: now,
+	}
+	s.State = newState
+	s.LastChange = now
+	return transition, true
+}
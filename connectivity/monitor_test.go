@@ -0,0 +1,194 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TargetState_update(t *testing.T) {
+	t.Parallel()
+
+	errDummy := errors.New("dummy")
+	now := time.Unix(1000, 0)
+
+	testCases := map[string]struct {
+		initial    TargetState
+		err        error
+		expected   TargetState
+		transition Transition
+		changed    bool
+	}{
+		"success below threshold": {
+			initial: TargetState{URL: "x", ConsecutiveFailures: 1},
+			expected: TargetState{URL: "x", ConsecutiveSuccesses: 1,
+				LastCheck: now},
+		},
+		"success reaching threshold": {
+			initial: TargetState{URL: "x", ConsecutiveSuccesses: 1},
+			expected: TargetState{URL: "x", State: StateUp,
+				ConsecutiveSuccesses: 2, LastCheck: now, LastChange: now},
+			transition: Transition{URL: "x", From: StateUnknown, To: StateUp, Time: now},
+			changed:    true,
+		},
+		"success while up": {
+			initial: TargetState{URL: "x", State: StateUp, ConsecutiveSuccesses: 5},
+			expected: TargetState{URL: "x", State: StateUp,
+				ConsecutiveSuccesses: 6, LastCheck: now},
+		},
+		"failure below threshold": {
+			initial: TargetState{URL: "x", State: StateUp, ConsecutiveSuccesses: 5},
+			err:     errDummy,
+			expected: TargetState{URL: "x", State: StateUp,
+				ConsecutiveFailures: 1, LastErr: errDummy, LastCheck: now},
+		},
+		"failure reaching threshold": {
+			initial: TargetState{URL: "x", State: StateUp, ConsecutiveFailures: 2},
+			err:     errDummy,
+			expected: TargetState{URL: "x", State: StateDown,
+				ConsecutiveFailures: 3, LastErr: errDummy,
+				LastCheck: now, LastChange: now},
+			transition: Transition{URL: "x", From: StateUp, To: StateDown,
+				Err: errDummy, Time: now},
+			changed: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			state := testCase.initial
+			const failureThreshold, successThreshold = 3, 2
+
+			transition, changed := state.update(testCase.err, now,
+				failureThreshold, successThreshold)
+
+			assert.Equal(t, testCase.expected, state)
+			assert.Equal(t, testCase.transition, transition)
+			assert.Equal(t, testCase.changed, changed)
+		})
+	}
+}
+
+func Test_TargetState_update_flapping(t *testing.T) {
+	t.Parallel()
+
+	errDummy := errors.New("dummy")
+	state := TargetState{URL: "x", State: StateUp}
+
+	for i := 0; i < 10; i++ {
+		var err error
+		if i%2 == 0 {
+			err = errDummy
+		}
+		_, changed := state.update(err, time.Now(), 2, 2)
+		assert.False(t, changed)
+	}
+	assert.Equal(t, StateUp, state.State)
+}
+
+func Test_NewMonitor(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		targets  []MonitorTarget
+		settings MonitorSettings
+		errMsg   string
+	}{
+		"negative interval": {
+			settings: MonitorSettings{Interval: -time.Second},
+			errMsg:   "monitor settings are not valid: interval -1s cannot be negative",
+		},
+		"negative failure threshold": {
+			settings: MonitorSettings{FailureThreshold: -1},
+			errMsg:   "monitor settings are not valid: failure threshold -1 cannot be negative",
+		},
+		"negative success threshold": {
+			settings: MonitorSettings{SuccessThreshold: -1},
+			errMsg:   "monitor settings are not valid: success threshold -1 cannot be negative",
+		},
+		"negative target interval": {
+			targets: []MonitorTarget{{URL: "x", Interval: -time.Second}},
+			errMsg: "monitor settings are not valid: " +
+				"interval -1s of target x cannot be negative",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			monitor, err := NewMonitor(testCase.targets, testCase.settings)
+
+			assert.Nil(t, monitor)
+			assert.ErrorIs(t, err, ErrMonitorSettingsInvalid)
+			assert.EqualError(t, err, testCase.errMsg)
+		})
+	}
+}
+
+func Test_Monitor(t *testing.T) {
+	t.Parallel()
+
+	errDummy := errors.New("dummy")
+	var failing atomic.Bool
+	checker := funcChecker(func(context.Context, string) error {
+		if failing.Load() {
+			return errDummy
+		}
+		return nil
+	})
+
+	var callbackCalls atomic.Int32
+	transitions := make(chan Transition)
+	monitor, err := NewMonitor([]MonitorTarget{
+		{URL: "x", Checker: checker},
+	}, MonitorSettings{
+		Interval:         time.Millisecond,
+		FailureThreshold: 3,
+		SuccessThreshold: 2,
+		OnTransition:     func(Transition) { callbackCalls.Add(1) },
+		Transitions:      transitions,
+	})
+	require.NoError(t, err)
+
+	states := monitor.Snapshot()
+	require.Len(t, states, 1)
+	assert.Equal(t, TargetState{URL: "x"}, states[0])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		monitor.Run(ctx)
+	}()
+
+	transition := <-transitions
+	assert.Equal(t, "x", transition.URL)
+	assert.Equal(t, StateUnknown, transition.From)
+	assert.Equal(t, StateUp, transition.To)
+	assert.NoError(t, transition.Err)
+
+	failing.Store(true)
+	transition = <-transitions
+	assert.Equal(t, StateUp, transition.From)
+	assert.Equal(t, StateDown, transition.To)
+	assert.ErrorIs(t, transition.Err, errDummy)
+
+	states = monitor.Snapshot()
+	require.Len(t, states, 1)
+	assert.Equal(t, StateDown, states[0].State)
+	assert.GreaterOrEqual(t, states[0].ConsecutiveFailures, 3)
+	assert.Equal(t, int32(2), callbackCalls.Load())
+
+	cancel()
+	<-done
+}
@@ -2,34 +2,150 @@ package connectivity
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 )
 
-type indexedError struct {
-	i   int
-	err error
+// FailFast defines when to stop running the remaining checks.
+type FailFast uint8
+
+const (
+	// FailFastNone runs all the checks.
+	FailFastNone FailFast = iota
+	// FailFastOnFailure cancels the remaining checks once a check
+	// fails, for when all checks must succeed.
+	FailFastOnFailure
+	// FailFastOnSuccess cancels the remaining checks once a check
+	// succeeds, for when any check succeeding is enough.
+	FailFastOnSuccess
+)
+
+// ParallelSettings are the settings to run checks in parallel.
+type ParallelSettings struct {
+	// MaxParallel is the maximum number of checks running at the
+	// same time, and defaults to zero which means no limit.
+	// It cannot be negative.
+	MaxParallel int
+	// FailFast defines when to cancel the remaining checks,
+	// and defaults to FailFastNone.
+	FailFast FailFast
 }
 
-func parallelChecks(ctx context.Context, checker SingleChecker,
-	urls []string) (errs []error) {
-	indexToURL := make(map[int]string, len(urls))
+var (
+	ErrCheckSkipped   = errors.New("check skipped")
+	ErrCheckSucceeded = errors.New("check succeeded")
+
+	ErrParallelSettingsInvalid = errors.New("parallel settings are not valid")
+)
+
+func (s *ParallelSettings) validate() (err error) {
+	switch {
+	case s.MaxParallel < 0:
+		return fmt.Errorf("%w: max parallel %d cannot be negative",
+			ErrParallelSettingsInvalid, s.MaxParallel)
+	case s.FailFast > FailFastOnSuccess:
+		return fmt.Errorf("%w: fail fast value %d is unknown",
+			ErrParallelSettingsInvalid, s.FailFast)
+	}
+	return nil
+}
+
+// ParallelChecksWithSettings runs the checks of each of the urls in
+// parallel with the checker given, with at most MaxParallel checks
+// running at the same time. Running checks are given a context
+// canceled when the fail fast condition is met, and checks not started
+// yet are skipped with an error wrapping ErrCheckSkipped and the cause,
+// which wraps the error of the failed check for FailFastOnFailure, or
+// ErrCheckSucceeded for FailFastOnSuccess. Checks are never skipped
+// because the context given is canceled, such that each check returns
+// its own error, as with ParallelChecks.
+// It returns a slice of errors with the same indexing and order as the
+// urls, meaning that some errors might be nil or not. You should ensure
+// to iterate over the errors and check each of them.
+// It returns an error wrapping ErrParallelSettingsInvalid without
+// running any check if MaxParallel is negative or FailFast is unknown.
+func ParallelChecksWithSettings(ctx context.Context, checker SingleChecker,
+	urls []string, settings ParallelSettings) (errs []error, err error) {
+	err = settings.validate()
+	if err != nil {
+		return nil, err
+	}
+
 	errs = make([]error, len(urls))
-	for i, url := range urls {
-		indexToURL[i] = url
+	runParallel(ctx, urls, settings,
+		func(ctx context.Context, i int) error {
+			errs[i] = checker.Check(ctx, urls[i])
+			return errs[i]
+		},
+		func(i int, err error) {
+			errs[i] = err
+		})
+	return errs, nil
+}
+
+// runParallel calls run for the index of each of the urls in parallel
+// with the settings given, and calls skip instead for the urls skipped
+// because the fail fast condition is met, with the skip error.
+func runParallel(ctx context.Context, urls []string, settings ParallelSettings,
+	run func(ctx context.Context, i int) error, skip func(i int, err error)) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// stopped is closed once the fail fast condition is met, to skip
+	// the remaining checks only in this case and not if the parent
+	// context is canceled.
+	stopped := make(chan struct{})
+	var stopOnce sync.Once
+	stop := func(cause error) {
+		stopOnce.Do(func() {
+			cancel(cause)
+			close(stopped)
+		})
 	}
 
-	indexedErrorsCh := make(chan indexedError)
-	for i, u := range indexToURL {
-		go func(i int, url string) {
-			err := checker.Check(ctx, url)
-			indexedErrorsCh <- indexedError{i: i, err: err}
-		}(i, u)
+	var semaphore chan struct{}
+	if settings.MaxParallel > 0 {
+		semaphore = make(chan struct{}, settings.MaxParallel)
 	}
 
-	for range urls {
-		checkErr := <-indexedErrorsCh
-		errs[checkErr.i] = checkErr.err
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		if semaphore != nil {
+			select {
+			case semaphore <- struct{}{}:
+			case <-stopped:
+			}
+		}
+
+		select {
+		case <-stopped:
+			skip(i, fmt.Errorf("%w: %w", ErrCheckSkipped, context.Cause(ctx)))
+			continue
+		default:
+		}
+
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			if semaphore != nil {
+				defer func() { <-semaphore }()
+			}
+			err := run(ctx, i)
+			switch {
+			case err != nil && settings.FailFast == FailFastOnFailure:
+				stop(fmt.Errorf("check of %s failed: %w", url, err))
+			case err == nil && settings.FailFast == FailFastOnSuccess:
+				stop(fmt.Errorf("%w: for %s", ErrCheckSucceeded, url))
+			}
+		}(i, url)
 	}
-	close(indexedErrorsCh)
+	wg.Wait()
+}
 
+func parallelChecks(ctx context.Context, checker SingleChecker,
+	urls []string) (errs []error) {
+	// The default settings are always valid.
+	errs, _ = ParallelChecksWithSettings(ctx, checker, urls, ParallelSettings{})
 	return errs
 }
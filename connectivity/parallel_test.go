@@ -0,0 +1,206 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrencyChecker checks urls named "fail" or "ok", and
+// records the maximum number of checks running at the same time.
+type concurrencyChecker struct {
+	mutex      sync.Mutex
+	running    int
+	maxRunning int
+}
+
+var errTestCheckFailed = errors.New("check failed")
+
+func (c *concurrencyChecker) Check(ctx context.Context, url string) error {
+	c.mutex.Lock()
+	c.running++
+	c.maxRunning = max(c.maxRunning, c.running)
+	c.mutex.Unlock()
+	defer func() {
+		c.mutex.Lock()
+		c.running--
+		c.mutex.Unlock()
+	}()
+
+	if url == "fail" {
+		return errTestCheckFailed
+	}
+
+	timer := time.NewTimer(20 * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func Test_ParallelChecksWithSettings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bounded parallelism", func(t *testing.T) {
+		t.Parallel()
+
+		urls := make([]string, 10)
+		for i := range urls {
+			urls[i] = "ok"
+		}
+		checker := &concurrencyChecker{}
+
+		errs, err := ParallelChecksWithSettings(context.Background(), checker, urls,
+			ParallelSettings{MaxParallel: 3})
+		require.NoError(t, err)
+
+		require.Len(t, errs, len(urls))
+		for _, err := range errs {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 3, checker.maxRunning)
+	})
+
+	t.Run("fail fast on failure", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &concurrencyChecker{}
+		urls := []string{"ok", "fail", "ok", "ok"}
+
+		errs, err := ParallelChecksWithSettings(context.Background(), checker, urls,
+			ParallelSettings{MaxParallel: 2, FailFast: FailFastOnFailure})
+		require.NoError(t, err)
+
+		require.Len(t, errs, len(urls))
+		assert.ErrorIs(t, errs[0], context.Canceled)
+		assert.ErrorIs(t, errs[1], errTestCheckFailed)
+		for _, err := range errs[2:] {
+			assert.ErrorIs(t, err, ErrCheckSkipped)
+			assert.ErrorIs(t, err, errTestCheckFailed)
+			assert.EqualError(t, err, "check skipped: check of fail failed: check failed")
+		}
+	})
+
+	t.Run("fail fast on success", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &concurrencyChecker{}
+		urls := []string{"fail", "ok", "ok"}
+
+		errs, err := ParallelChecksWithSettings(context.Background(), checker, urls,
+			ParallelSettings{MaxParallel: 2, FailFast: FailFastOnSuccess})
+		require.NoError(t, err)
+
+		require.Len(t, errs, len(urls))
+		assert.ErrorIs(t, errs[0], errTestCheckFailed)
+		// The two ok checks run concurrently, so one of them
+		// succeeds and the other one may be canceled.
+		successes := 0
+		for _, err := range errs[1:] {
+			if err == nil {
+				successes++
+				continue
+			}
+			assert.ErrorIs(t, err, context.Canceled)
+		}
+		assert.Positive(t, successes)
+	})
+
+	t.Run("unbounded", func(t *testing.T) {
+		t.Parallel()
+
+		urls := make([]string, 20)
+		for i := range urls {
+			urls[i] = "ok"
+		}
+		checker := &concurrencyChecker{}
+
+		errs, err := ParallelChecksWithSettings(context.Background(), checker, urls, ParallelSettings{})
+		require.NoError(t, err)
+
+		require.Len(t, errs, len(urls))
+		for _, err := range errs {
+			assert.NoError(t, err)
+		}
+		assert.Greater(t, checker.maxRunning, 3)
+	})
+}
+
+func Test_ParallelChecksWithSettings_parentCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	settingsCases := map[string]ParallelSettings{
+		"no settings": {},
+		"bounded fail fast": {
+			MaxParallel: 1,
+			FailFast:    FailFastOnSuccess,
+		},
+	}
+
+	for name, settings := range settingsCases {
+		settings := settings
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := &concurrencyChecker{}
+			urls := []string{"ok", "fail", "ok"}
+
+			errs, err := ParallelChecksWithSettings(ctx, checker, urls, settings)
+			require.NoError(t, err)
+
+			// Each check runs and returns its own error.
+			require.Len(t, errs, len(urls))
+			assert.ErrorIs(t, errs[0], context.Canceled)
+			assert.NotErrorIs(t, errs[0], ErrCheckSkipped)
+			assert.ErrorIs(t, errs[1], errTestCheckFailed)
+			assert.ErrorIs(t, errs[2], context.Canceled)
+			assert.NotErrorIs(t, errs[2], ErrCheckSkipped)
+		})
+	}
+}
+
+func Test_ParallelChecksWithSettings_invalidSettings(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		settings ParallelSettings
+		errMsg   string
+	}{
+		"negative max parallel": {
+			settings: ParallelSettings{MaxParallel: -1},
+			errMsg:   "parallel settings are not valid: max parallel -1 cannot be negative",
+		},
+		"unknown fail fast": {
+			settings: ParallelSettings{FailFast: 3},
+			errMsg:   "parallel settings are not valid: fail fast value 3 is unknown",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := &concurrencyChecker{}
+
+			errs, err := ParallelChecksWithSettings(context.Background(),
+				checker, []string{"ok"}, testCase.settings)
+
+			assert.Nil(t, errs)
+			assert.ErrorIs(t, err, ErrParallelSettingsInvalid)
+			assert.EqualError(t, err, testCase.errMsg)
+			assert.Zero(t, checker.maxRunning)
+		})
+	}
+}
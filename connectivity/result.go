@@ -0,0 +1,74 @@
+package connectivity
+
+import (
+	"context"
+	"time"
+)
+
+// Result is the result of a check.
+type Result struct {
+	// URL is the url checked.
+	URL string
+	// Err is the error of the check, and is nil if it succeeded.
+	Err error
+	// Duration is the total duration of the check.
+	Duration time.Duration
+	// Timings are the timings of each phase of the check, and
+	// are only set for checkers implementing TimingsChecker.
+	Timings Timings
+}
+
+// CheckResult runs the check of the url with the checker given
+// and returns its result, including its duration and, if the
+// checker implements TimingsChecker, its timings.
+func CheckResult(ctx context.Context, checker SingleChecker, url string) (result Result) {
+	result.URL = url
+	start := time.Now()
+	timingsChecker, ok := checker.(TimingsChecker)
+	if ok {
+		result.Timings, result.Err = timingsChecker.CheckTimings(ctx, url)
+	} else {
+		result.Err = checker.Check(ctx, url)
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// ParallelCheckResults is the result returning variant of
+// ParallelChecksWithSettings, running the checks of each of the urls in
+// parallel with the checker and settings given. It returns a slice of
+// results with the same indexing and order as the urls. The results of
+// the checks skipped only have their URL and Err fields set.
+// It returns an error wrapping ErrParallelSettingsInvalid without
+// running any check if the settings are not valid.
+func ParallelCheckResults(ctx context.Context, checker SingleChecker,
+	urls []string, settings ParallelSettings) (results []Result, err error) {
+	err = settings.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	results = make([]Result, len(urls))
+	runParallel(ctx, urls, settings,
+		func(ctx context.Context, i int) error {
+			results[i] = CheckResult(ctx, checker, urls[i])
+			return results[i].Err
+		},
+		func(i int, err error) {
+			results[i] = Result{URL: urls[i], Err: err}
+		})
+	return results, nil
+}
+
+// RepeatCheckResults runs the check of the url with the checker given
+// count times sequentially, and returns the results in order. It
+// stops early if the context is canceled. The results can be
+// aggregated with ComputeStats.
+func RepeatCheckResults(ctx context.Context, checker SingleChecker,
+	url string, count int) (results []Result) {
+	results = make([]Result, 0, count)
+	for i := 0; i < count && ctx.Err() == nil; i++ {
+		results = append(results, CheckResult(ctx, checker, url))
+	}
+	return results
+}
@@ -0,0 +1,129 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type funcChecker func(ctx context.Context, url string) error
+
+func (f funcChecker) Check(ctx context.Context, url string) error {
+	return f(ctx, url)
+}
+
+func Test_CheckResult(t *testing.T) {
+	t.Parallel()
+
+	t.Run("https timings", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewTLSServer(http.NotFoundHandler())
+		t.Cleanup(server.Close)
+		checker := NewHTTPSGetChecker(server.Client(), http.StatusNotFound)
+
+		result := CheckResult(context.Background(), checker, server.URL)
+
+		require.NoError(t, result.Err)
+		assert.Equal(t, server.URL, result.URL)
+		assert.Positive(t, result.Timings.Connect)
+		assert.Positive(t, result.Timings.TLS)
+		assert.Positive(t, result.Timings.FirstByte)
+		assert.GreaterOrEqual(t, result.Duration, result.Timings.FirstByte)
+	})
+
+	t.Run("http dns timing", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(server.Close)
+		url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+		checker := NewHTTPGetChecker(&http.Client{}, http.StatusOK)
+
+		result := CheckResult(context.Background(), checker, url)
+
+		assert.ErrorIs(t, result.Err, ErrHTTPStatusUnexpected)
+		assert.Positive(t, result.Timings.DNS)
+		assert.Positive(t, result.Timings.FirstByte)
+		assert.Zero(t, result.Timings.TLS)
+	})
+
+	t.Run("checker without timings", func(t *testing.T) {
+		t.Parallel()
+
+		errDummy := errors.New("dummy")
+		checker := funcChecker(func(context.Context, string) error { return errDummy })
+
+		result := CheckResult(context.Background(), checker, "x")
+
+		assert.Equal(t, Result{URL: "x", Err: errDummy, Duration: result.Duration}, result)
+		assert.Positive(t, result.Duration)
+	})
+}
+
+func Test_ParallelCheckResults(t *testing.T) {
+	t.Parallel()
+
+	errDummy := errors.New("dummy")
+	checker := funcChecker(func(_ context.Context, url string) error {
+		if url == "bad" {
+			return errDummy
+		}
+		return nil
+	})
+
+	t.Run("all checks", func(t *testing.T) {
+		t.Parallel()
+
+		results, err := ParallelCheckResults(context.Background(), checker,
+			[]string{"good", "bad", "good"}, ParallelSettings{MaxParallel: 2})
+		require.NoError(t, err)
+
+		require.Len(t, results, 3)
+		for i, url := range []string{"good", "bad", "good"} {
+			assert.Equal(t, url, results[i].URL)
+		}
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, errDummy)
+		assert.NoError(t, results[2].Err)
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		t.Parallel()
+
+		results, err := ParallelCheckResults(context.Background(), checker,
+			[]string{"bad", "good"}, ParallelSettings{MaxParallel: 1, FailFast: FailFastOnFailure})
+		require.NoError(t, err)
+
+		require.Len(t, results, 2)
+		assert.ErrorIs(t, results[0].Err, errDummy)
+		assert.Equal(t, "good", results[1].URL)
+		assert.ErrorIs(t, results[1].Err, ErrCheckSkipped)
+		assert.Zero(t, results[1].Duration)
+	})
+}
+
+func Test_RepeatCheckResults(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	checker := funcChecker(func(context.Context, string) error {
+		calls++
+		if calls == 3 {
+			cancel()
+		}
+		return nil
+	})
+
+	results := RepeatCheckResults(ctx, checker, "x", 10)
+
+	assert.Len(t, results, 3)
+}
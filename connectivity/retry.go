@@ -0,0 +1,231 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Classifier returns true if a check error can be retried.
+type Classifier func(err error) (retryable bool)
+
+// DefaultClassifier considers all check errors retryable, except
+// HTTP client error status codes other than 408 Request Timeout
+// and 429 Too Many Requests, which are unlikely to change on retry.
+func DefaultClassifier(err error) (retryable bool) {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+
+	switch statusErr.Received {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	default:
+		isClientError := statusErr.Received >= http.StatusBadRequest &&
+			statusErr.Received < http.StatusInternalServerError
+		return !isClientError
+	}
+}
+
+// RetrySettings are the settings for a RetryChecker.
+type RetrySettings struct {
+	// Classifier decides if a check error can be retried,
+	// and defaults to DefaultClassifier.
+	Classifier Classifier
+	// MaxAttempts is the maximum number of attempts, and defaults to 3.
+	MaxAttempts int
+	// AttemptTimeout is the timeout for each attempt, and defaults
+	// to zero which means no timeout other than the context deadline.
+	AttemptTimeout time.Duration
+	// InitialBackoff is the duration to wait for before the second
+	// attempt, and defaults to 500 milliseconds.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum duration to wait for between
+	// two attempts, and defaults to 10 seconds.
+	MaxBackoff time.Duration
+	// Multiplier is the factor applied to the backoff duration
+	// after each attempt, and defaults to 2.
+	Multiplier float64
+	// Jitter is the fraction of the backoff duration randomly added
+	// or removed to spread attempts, and defaults to 0.2. It can be
+	// set to a negative value to disable jitter, and cannot be
+	// greater than 1.
+	Jitter float64
+}
+
+func (s *RetrySettings) setDefaults() {
+	if s.Classifier == nil {
+		s.Classifier = DefaultClassifier
+	}
+	if s.MaxAttempts == 0 {
+		const defaultMaxAttempts = 3
+		s.MaxAttempts = defaultMaxAttempts
+	}
+	if s.InitialBackoff == 0 {
+		const defaultInitialBackoff = 500 * time.Millisecond
+		s.InitialBackoff = defaultInitialBackoff
+	}
+	if s.MaxBackoff == 0 {
+		const defaultMaxBackoff = 10 * time.Second
+		s.MaxBackoff = defaultMaxBackoff
+	}
+	if s.Multiplier == 0 {
+		const defaultMultiplier = 2
+		s.Multiplier = defaultMultiplier
+	}
+	if s.Jitter == 0 {
+		const defaultJitter = 0.2
+		s.Jitter = defaultJitter
+	}
+}
+
+var ErrRetrySettingsInvalid = errors.New("retry settings are not valid")
+
+func (s *RetrySettings) validate() (err error) {
+	switch {
+	case s.MaxAttempts < 0:
+		return fmt.Errorf("%w: max attempts %d cannot be negative",
+			ErrRetrySettingsInvalid, s.MaxAttempts)
+	case s.AttemptTimeout < 0:
+		return fmt.Errorf("%w: attempt timeout %s cannot be negative",
+			ErrRetrySettingsInvalid, s.AttemptTimeout)
+	case s.InitialBackoff < 0:
+		return fmt.Errorf("%w: initial backoff %s cannot be negative",
+			ErrRetrySettingsInvalid, s.InitialBackoff)
+	case s.MaxBackoff < 0:
+		return fmt.Errorf("%w: maximum backoff %s cannot be negative",
+			ErrRetrySettingsInvalid, s.MaxBackoff)
+	case s.Multiplier < 0:
+		return fmt.Errorf("%w: multiplier %g cannot be negative",
+			ErrRetrySettingsInvalid, s.Multiplier)
+	case s.Jitter > 1:
+		return fmt.Errorf("%w: jitter %g cannot be greater than 1",
+			ErrRetrySettingsInvalid, s.Jitter)
+	}
+	return nil
+}
+
+// NewRetryChecker creates a new checker retrying the checks of
+// the checker given on retryable errors, with an exponential backoff.
+// It returns an error wrapping ErrRetrySettingsInvalid if a setting
+// is negative or if the jitter is greater than 1.
+func NewRetryChecker(checker SingleChecker, settings RetrySettings) (
+	retryChecker *RetryChecker, err error) {
+	settings.setDefaults()
+	err = settings.validate()
+	if err != nil {
+		return nil, err
+	}
+	return &RetryChecker{
+		checker:  checker,
+		settings: settings,
+	}, nil
+}
+
+// RetryChecker implements a checker retrying the checks of another
+// checker on retryable errors.
+type RetryChecker struct {
+	checker  SingleChecker
+	settings RetrySettings
+}
+
+// ParallelChecks runs the checks of each of the urls in parallel,
+// retrying each check on retryable errors.
+// It returns a slice of errors with the same indexing and order as the
+// urls, meaning that some errors might be nil or not. You should ensure
+// to iterate over the errors and check each of them.
+func (c *RetryChecker) ParallelChecks(ctx context.Context, urls []string) (errs []error) {
+	return parallelChecks(ctx, c, urls)
+}
+
+var (
+	ErrRetryNotRetryable = errors.New("check failed with a non retryable error")
+	ErrRetryExhausted    = errors.New("check failed on all attempts")
+)
+
+// RetryError is returned by the RetryChecker when all the attempts
+// failed, a non retryable error occurred or the context was canceled.
+type RetryError struct {
+	// Attempts contains the error of each failed attempt, in order.
+	Attempts []error
+	// Reason is ErrRetryNotRetryable, ErrRetryExhausted
+	// or the context error.
+	Reason error
+}
+
+func (e *RetryError) Error() string {
+	attemptMessages := make([]string, len(e.Attempts))
+	for i, err := range e.Attempts {
+		attemptMessages[i] = fmt.Sprintf("attempt %d: %s", i+1, err)
+	}
+	return fmt.Sprintf("%s: %s", e.Reason, strings.Join(attemptMessages, "; "))
+}
+
+// Unwrap returns the reason error and the errors of all the attempts.
+func (e *RetryError) Unwrap() []error {
+	return append([]error{e.Reason}, e.Attempts...)
+}
+
+// Check runs the check of the url, and runs it again on retryable
+// errors until it succeeds or the maximum number of attempts is
+// reached. It returns an error of type *RetryError if it did not
+// succeed.
+func (c *RetryChecker) Check(ctx context.Context, url string) error {
+	retryErr := &RetryError{}
+	backoff := c.settings.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := c.checkAttempt(ctx, url)
+		if err == nil {
+			return nil
+		}
+		retryErr.Attempts = append(retryErr.Attempts, err)
+
+		switch {
+		case ctx.Err() != nil:
+			retryErr.Reason = ctx.Err()
+			return retryErr
+		case !c.settings.Classifier(err):
+			retryErr.Reason = ErrRetryNotRetryable
+			return retryErr
+		case attempt >= c.settings.MaxAttempts:
+			retryErr.Reason = ErrRetryExhausted
+			return retryErr
+		}
+
+		timer := time.NewTimer(withJitter(backoff, c.settings.Jitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			retryErr.Reason = ctx.Err()
+			return retryErr
+		case <-timer.C:
+		}
+
+		backoff = time.Duration(float64(backoff) * c.settings.Multiplier)
+		backoff = min(backoff, c.settings.MaxBackoff)
+	}
+}
+
+func (c *RetryChecker) checkAttempt(ctx context.Context, url string) error {
+	if c.settings.AttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.settings.AttemptTimeout)
+		defer cancel()
+	}
+	return c.checker.Check(ctx, url)
+}
+
+// withJitter returns the duration given randomly increased or
+// decreased by up to the jitter fraction of it.
+func withJitter(duration time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return duration
+	}
+	factor := 1 + jitter*(2*rand.Float64()-1) //nolint:gosec,gomnd
+	return time.Duration(float64(duration) * factor)
+}
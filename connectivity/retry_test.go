@@ -0,0 +1,208 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RetryChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	errDummy := errors.New("dummy")
+
+	t.Run("success after retries", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		checker := funcChecker(func(context.Context, string) error {
+			if calls.Add(1) < 3 {
+				return errDummy
+			}
+			return nil
+		})
+		retryChecker, err := NewRetryChecker(checker, RetrySettings{
+			InitialBackoff: time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		err = retryChecker.Check(context.Background(), "x")
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		checker := funcChecker(func(context.Context, string) error { return errDummy })
+		retryChecker, err := NewRetryChecker(checker, RetrySettings{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+			Jitter:         -1,
+		})
+		require.NoError(t, err)
+
+		err = retryChecker.Check(context.Background(), "x")
+
+		assert.ErrorIs(t, err, ErrRetryExhausted)
+		assert.ErrorIs(t, err, errDummy)
+		assert.EqualError(t, err, "check failed on all attempts: attempt 1: dummy; attempt 2: dummy")
+	})
+
+	t.Run("http client error not retryable", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		t.Cleanup(server.Close)
+		retryChecker, err := NewRetryChecker(NewHTTPGetChecker(server.Client(), http.StatusOK),
+			RetrySettings{InitialBackoff: time.Millisecond})
+		require.NoError(t, err)
+
+		err = retryChecker.Check(context.Background(), server.URL)
+
+		assert.ErrorIs(t, err, ErrRetryNotRetryable)
+		var statusErr *HTTPStatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusForbidden, statusErr.Received)
+		assert.EqualError(t, err, "check failed with a non retryable error: attempt 1: "+
+			"unexpected HTTP status received: expected 200 and received 403 Forbidden")
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("attempt timeout", func(t *testing.T) {
+		t.Parallel()
+
+		checker := funcChecker(func(ctx context.Context, _ string) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		retryChecker, err := NewRetryChecker(checker, RetrySettings{
+			MaxAttempts:    2,
+			AttemptTimeout: time.Millisecond,
+			InitialBackoff: time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		err = retryChecker.Check(context.Background(), "x")
+
+		assert.ErrorIs(t, err, ErrRetryExhausted)
+		var retryErr *RetryError
+		require.ErrorAs(t, err, &retryErr)
+		require.Len(t, retryErr.Attempts, 2)
+		assert.ErrorIs(t, retryErr.Attempts[1], context.DeadlineExceeded)
+	})
+
+	t.Run("context canceled during backoff", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		checker := funcChecker(func(context.Context, string) error { return errDummy })
+		retryChecker, err := NewRetryChecker(checker, RetrySettings{
+			InitialBackoff: time.Hour,
+		})
+		require.NoError(t, err)
+
+		err = retryChecker.Check(ctx, "x")
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.EqualError(t, err, "context deadline exceeded: attempt 1: dummy")
+	})
+}
+
+func Test_DefaultClassifier(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		err       error
+		retryable bool
+	}{
+		"other error": {
+			err:       errors.New("dummy"),
+			retryable: true,
+		},
+		"not found": {
+			err:       &HTTPStatusError{Received: http.StatusNotFound},
+			retryable: false,
+		},
+		"too many requests": {
+			err:       &HTTPStatusError{Received: http.StatusTooManyRequests},
+			retryable: true,
+		},
+		"service unavailable": {
+			err:       &HTTPStatusError{Received: http.StatusServiceUnavailable},
+			retryable: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, testCase.retryable, DefaultClassifier(testCase.err))
+		})
+	}
+}
+
+func Test_withJitter(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, time.Second, withJitter(time.Second, 0))
+	for i := 0; i < 100; i++ {
+		duration := withJitter(time.Second, 0.2)
+		assert.GreaterOrEqual(t, duration, 800*time.Millisecond)
+		assert.LessOrEqual(t, duration, 1200*time.Millisecond)
+	}
+}
+
+func Test_NewRetryChecker(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		settings RetrySettings
+		errMsg   string
+	}{
+		"negative max attempts": {
+			settings: RetrySettings{MaxAttempts: -1},
+			errMsg:   "retry settings are not valid: max attempts -1 cannot be negative",
+		},
+		"negative initial backoff": {
+			settings: RetrySettings{InitialBackoff: -time.Second},
+			errMsg:   "retry settings are not valid: initial backoff -1s cannot be negative",
+		},
+		"negative max backoff": {
+			settings: RetrySettings{MaxBackoff: -time.Second},
+			errMsg:   "retry settings are not valid: maximum backoff -1s cannot be negative",
+		},
+		"jitter greater than 1": {
+			settings: RetrySettings{Jitter: 1.5},
+			errMsg:   "retry settings are not valid: jitter 1.5 cannot be greater than 1",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := funcChecker(func(context.Context, string) error { return nil })
+			retryChecker, err := NewRetryChecker(checker, testCase.settings)
+
+			assert.Nil(t, retryChecker)
+			assert.ErrorIs(t, err, ErrRetrySettingsInvalid)
+			assert.EqualError(t, err, testCase.errMsg)
+		})
+	}
+}
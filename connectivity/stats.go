@@ -0,0 +1,60 @@
+package connectivity
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Stats are aggregated statistics of the durations of
+// successful check results.
+type Stats struct {
+	// Count is the number of successful results.
+	Count int
+	// Failures is the number of failed results.
+	Failures int
+	Min      time.Duration
+	Max      time.Duration
+	Avg      time.Duration
+	P50      time.Duration
+	P95      time.Duration
+}
+
+// ComputeStats computes statistics on the durations of the
+// successful results given. Failed results are only counted,
+// and the durations are all zero if no result succeeded.
+func ComputeStats(results []Result) (stats Stats) {
+	durations := make([]time.Duration, 0, len(results))
+	for _, result := range results {
+		if result.Err != nil {
+			stats.Failures++
+			continue
+		}
+		durations = append(durations, result.Duration)
+	}
+
+	stats.Count = len(durations)
+	if stats.Count == 0 {
+		return stats
+	}
+
+	slices.Sort(durations)
+	stats.Min = durations[0]
+	stats.Max = durations[len(durations)-1]
+	var sum time.Duration
+	for _, duration := range durations {
+		sum += duration
+	}
+	stats.Avg = sum / time.Duration(len(durations))
+	const p50, p95 = 0.5, 0.95
+	stats.P50 = percentile(durations, p50)
+	stats.P95 = percentile(durations, p95)
+	return stats
+}
+
+// percentile returns the percentile of the sorted durations
+// given using the nearest-rank method.
+func percentile(sorted []time.Duration, fraction float64) time.Duration {
+	rank := int(math.Ceil(fraction * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
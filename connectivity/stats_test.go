@@ -0,0 +1,60 @@
+package connectivity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ComputeStats(t *testing.T) {
+	t.Parallel()
+
+	errDummy := errors.New("dummy")
+
+	testCases := map[string]struct {
+		results []Result
+		stats   Stats
+	}{
+		"no result": {},
+		"failures only": {
+			results: []Result{{Err: errDummy, Duration: time.Second}},
+			stats:   Stats{Failures: 1},
+		},
+		"single result": {
+			results: []Result{{Duration: time.Second}},
+			stats: Stats{Count: 1, Min: time.Second, Max: time.Second,
+				Avg: time.Second, P50: time.Second, P95: time.Second},
+		},
+		"multiple results": {
+			results: func() (results []Result) {
+				// 20 down to 1 milliseconds, and one failure
+				for i := 20; i > 0; i-- {
+					results = append(results, Result{Duration: time.Duration(i) * time.Millisecond})
+				}
+				return append(results, Result{Err: errDummy, Duration: time.Hour})
+			}(),
+			stats: Stats{
+				Count:    20,
+				Failures: 1,
+				Min:      time.Millisecond,
+				Max:      20 * time.Millisecond,
+				Avg:      10500 * time.Microsecond,
+				P50:      10 * time.Millisecond,
+				P95:      19 * time.Millisecond,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stats := ComputeStats(testCase.results)
+
+			assert.Equal(t, testCase.stats, stats)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package connectivity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// NewTCPChecker creates a new TCP checker connecting with the
+// dialer given within the timeout given, where a zero timeout
+// means no timeout other than the context deadline. If expectedBanner is
+// not empty, the server must send data starting with it once
+// the connection is established.
+func NewTCPChecker(dialer *net.Dialer, timeout time.Duration,
+	expectedBanner string) *TCPChecker {
+	return &TCPChecker{
+		dialer:         dialer,
+		timeout:        timeout,
+		expectedBanner: expectedBanner,
+	}
+}
+
+// TCPChecker implements a checker to establish TCP connections
+// and optionally verify the banner sent by the server.
+type TCPChecker struct {
+	dialer         *net.Dialer
+	timeout        time.Duration
+	expectedBanner string
+}
+
+// ParallelChecks verifies a TCP connection can be established to
+// each of the addresses, given as host:port or as urls.
+// It returns a slice of errors with the same indexing and order as the
+// urls, meaning that some errors might be nil or not. You should ensure
+// to iterate over the errors and check each of them.
+func (c *TCPChecker) ParallelChecks(ctx context.Context, urls []string) (errs []error) {
+	return parallelChecks(ctx, c, urls)
+}
+
+var ErrTCPBannerUnexpected = errors.New("unexpected TCP banner received")
+
+// Check verifies a TCP connection can be established to the address,
+// given as host:port or as an url, and that the server sends the
+// expected banner if one is set.
+func (c *TCPChecker) Check(ctx context.Context, url string) (err error) {
+	address, err := parseAddress("tcp", url)
+	if err != nil {
+		return err
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	connection, err := c.dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("dialing: %w", err)
+	}
+	defer connection.Close()
+
+	if c.expectedBanner == "" {
+		return nil
+	}
+
+	stop := closeOnDone(ctx, connection)
+	defer stop()
+
+	banner := make([]byte, len(c.expectedBanner))
+	n, err := io.ReadFull(connection, banner)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("reading banner: %w", contextOrError(ctx, err))
+	}
+	banner = banner[:n]
+
+	if string(banner) != c.expectedBanner {
+		return fmt.Errorf("%w: expected %q and received %q",
+			ErrTCPBannerUnexpected, c.expectedBanner, banner)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package connectivity
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenTCP listens on a local TCP port and writes the banner
+// given to each connection accepted. It returns the listening
+// address.
+func listenTCP(t *testing.T, banner string) (address string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if banner != "" {
+				_, _ = connection.Write([]byte(banner))
+			}
+			go func() {
+				// Hold the connection open until the client closes it.
+				_, _ = connection.Read(make([]byte, 1))
+				_ = connection.Close()
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func Test_TCPChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := closedListener.Addr().String()
+	require.NoError(t, closedListener.Close())
+
+	smtpAddress := listenTCP(t, "220 mail.example.com ESMTP\r\n")
+	silentAddress := listenTCP(t, "")
+
+	testCases := map[string]struct {
+		url            string
+		expectedBanner string
+		errWrapped     error
+		errMessage     string
+	}{
+		"connection success": {
+			url: silentAddress,
+		},
+		"url": {
+			url:            "smtp://" + smtpAddress,
+			expectedBanner: "220 ",
+		},
+		"connection refused": {
+			url:        closedAddress,
+			errMessage: "dialing: dial tcp " + closedAddress + ": connect: connection refused",
+		},
+		"banner unexpected": {
+			url:            smtpAddress,
+			expectedBanner: "SSH-2.0",
+			errWrapped:     ErrTCPBannerUnexpected,
+			errMessage:     `unexpected TCP banner received: expected "SSH-2.0" and received "220 mai"`,
+		},
+		"banner timeout": {
+			url:            silentAddress,
+			expectedBanner: "220 ",
+			errWrapped:     context.DeadlineExceeded,
+			errMessage:     "reading banner: context deadline exceeded",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			const timeout = 100 * time.Millisecond
+			checker := NewTCPChecker(&net.Dialer{}, timeout, testCase.expectedBanner)
+
+			err := checker.Check(context.Background(), testCase.url)
+
+			if testCase.errMessage == "" {
+				require.NoError(t, err)
+				return
+			}
+			if testCase.errWrapped != nil {
+				assert.ErrorIs(t, err, testCase.errWrapped)
+			}
+			assert.EqualError(t, err, testCase.errMessage)
+		})
+	}
+}
+
+func Test_TCPChecker_ParallelChecks(t *testing.T) {
+	t.Parallel()
+
+	address := listenTCP(t, "")
+	checker := NewMixedChecker([]Checker{
+		NewTCPChecker(&net.Dialer{}, time.Second, ""),
+		NewUDPChecker(&net.Dialer{}, time.Second, []byte("x"), nil),
+	})
+
+	errs := checker.ParallelChecks(context.Background(), []string{address, "invalid"})
+
+	require.Len(t, errs, 2)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrOneOrMoreChecksFailed)
+}
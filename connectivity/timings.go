@@ -0,0 +1,98 @@
+package connectivity
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings are the durations of each phase of a check.
+// A phase duration is zero if the phase did not happen, for
+// example if an existing connection was reused.
+type Timings struct {
+	// DNS is the duration of the DNS resolution.
+	DNS time.Duration
+	// Connect is the duration to establish the connection.
+	Connect time.Duration
+	// TLS is the duration of the TLS handshake.
+	TLS time.Duration
+	// FirstByte is the duration from the start of the request
+	// to the first byte of the response.
+	FirstByte time.Duration
+}
+
+// TimingsChecker is a checker reporting the timings of each
+// phase of a check. It is implemented by the HTTPGetChecker
+// and HTTPSGetChecker.
+type TimingsChecker interface {
+	CheckTimings(ctx context.Context, url string) (timings Timings, err error)
+}
+
+// timingsTracer records the timings of an HTTP request
+// using an httptrace.ClientTrace.
+type timingsTracer struct {
+	mutex        sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timings      Timings
+}
+
+func newTimingsTracer() *timingsTracer {
+	return &timingsTracer{
+		start: time.Now(),
+	}
+}
+
+// withClientTrace returns a context with an httptrace.ClientTrace
+// recording the timings.
+func (t *timingsTracer) withClientTrace(ctx context.Context) context.Context {
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.record(func() { t.dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.record(func() { t.timings.DNS = time.Since(t.dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			t.record(func() {
+				// Several connections can be attempted in parallel,
+				// so only the first connection start is recorded.
+				if t.connectStart.IsZero() {
+					t.connectStart = time.Now()
+				}
+			})
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err != nil {
+				return
+			}
+			t.record(func() { t.timings.Connect = time.Since(t.connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			t.record(func() { t.tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.record(func() { t.timings.TLS = time.Since(t.tlsStart) })
+		},
+		GotFirstResponseByte: func() {
+			t.record(func() { t.timings.FirstByte = time.Since(t.start) })
+		},
+	}
+	return httptrace.WithClientTrace(ctx, trace)
+}
+
+func (t *timingsTracer) record(f func()) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	f()
+}
+
+func (t *timingsTracer) getTimings() Timings {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.timings
+}
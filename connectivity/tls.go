@@ -0,0 +1,169 @@
+package connectivity
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+)
+
+// TLSSettings are the settings for a TLSChecker.
+type TLSSettings struct {
+	// Timeout is the timeout for the connection and TLS handshake,
+	// and defaults to zero which means no timeout other than the
+	// context deadline.
+	Timeout time.Duration
+	// ServerName overrides the server name sent for SNI and used to
+	// verify the certificate hostname. It defaults to the host of
+	// the address checked.
+	ServerName string
+	// RootCAs is the pool of root certificates to verify the chain
+	// of certificates with, and defaults to the system pool if nil.
+	RootCAs *x509.CertPool
+	// MinDaysUntilExpiry is the minimum number of days before any
+	// certificate of the chain expires. It defaults to zero, which
+	// only fails for expired certificates.
+	MinDaysUntilExpiry int
+	// MinVersion is the minimum TLS version negotiated, such as
+	// tls.VersionTLS12. It defaults to zero, which accepts any version.
+	MinVersion uint16
+	// CipherSuites is the list of cipher suites allowed to be
+	// negotiated. It defaults to nil, which accepts any cipher suite.
+	CipherSuites []uint16
+}
+
+// NewTLSChecker creates a new TLS checker connecting with the
+// dialer given and verifying the TLS server with the settings given.
+func NewTLSChecker(dialer *net.Dialer, settings TLSSettings) *TLSChecker {
+	return &TLSChecker{
+		dialer:   dialer,
+		settings: settings,
+	}
+}
+
+// TLSChecker implements a checker to perform TLS handshakes and
+// verify the health of the TLS server and of its certificates.
+type TLSChecker struct {
+	dialer   *net.Dialer
+	settings TLSSettings
+}
+
+// ParallelChecks verifies the TLS server and certificates of each of
+// the addresses, given as host:port or as urls.
+// It returns a slice of errors with the same indexing and order as the
+// urls, meaning that some errors might be nil or not. You should ensure
+// to iterate over the errors and check each of them.
+func (c *TLSChecker) ParallelChecks(ctx context.Context, urls []string) (errs []error) {
+	return parallelChecks(ctx, c, urls)
+}
+
+var (
+	ErrTLSChainUntrusted        = errors.New("TLS certificate chain is not trusted")
+	ErrTLSHostnameMismatch      = errors.New("TLS certificate hostname does not match")
+	ErrTLSCertificateExpiring   = errors.New("TLS certificate expires too soon")
+	ErrTLSVersionTooLow         = errors.New("TLS version is too low")
+	ErrTLSCipherSuiteNotAllowed = errors.New("TLS cipher suite is not allowed")
+)
+
+// Check performs a TLS handshake with the address, given as host:port
+// or as an url, and verifies the certificate chain is trusted, the
+// hostname matches, no certificate expires too soon, and the TLS
+// version and cipher suite negotiated are allowed.
+// Each failing property is returned as an error wrapping one of
+// ErrTLSChainUntrusted, ErrTLSHostnameMismatch, ErrTLSCertificateExpiring,
+// ErrTLSVersionTooLow and ErrTLSCipherSuiteNotAllowed, joined together.
+func (c *TLSChecker) Check(ctx context.Context, url string) (err error) {
+	address, err := parseAddress("tcp", url)
+	if err != nil {
+		return err
+	}
+
+	serverName := c.settings.ServerName
+	if serverName == "" {
+		serverName, _, _ = net.SplitHostPort(address)
+	}
+
+	if c.settings.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.settings.Timeout)
+		defer cancel()
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: c.dialer,
+		Config: &tls.Config{
+			ServerName: serverName,
+			// The certificates are verified after the handshake
+			// to report each failing property separately.
+			InsecureSkipVerify: true, //nolint:gosec
+			// Accept any version to report it if it is too low.
+			MinVersion: tls.VersionTLS10, //nolint:gosec
+		},
+	}
+	connection, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("dialing: %w", err)
+	}
+	defer connection.Close()
+
+	state := connection.(*tls.Conn).ConnectionState() //nolint:forcetypeassert
+	return errors.Join(c.checkState(state, serverName, time.Now())...)
+}
+
+func (c *TLSChecker) checkState(state tls.ConnectionState,
+	serverName string, now time.Time) (errs []error) {
+	certificates := state.PeerCertificates
+	if len(certificates) == 0 {
+		return []error{fmt.Errorf("%w: no certificate received", ErrTLSChainUntrusted)}
+	}
+	leaf := certificates[0]
+
+	intermediates := x509.NewCertPool()
+	for _, certificate := range certificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         c.settings.RootCAs,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%w: %w", ErrTLSChainUntrusted, err))
+	}
+
+	err = leaf.VerifyHostname(serverName)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%w: %w", ErrTLSHostnameMismatch, err))
+	}
+
+	minExpiry := now.AddDate(0, 0, c.settings.MinDaysUntilExpiry)
+	for _, certificate := range certificates {
+		if certificate.NotAfter.After(minExpiry) {
+			continue
+		}
+		const day = 24 * time.Hour
+		daysLeft := int(certificate.NotAfter.Sub(now) / day)
+		errs = append(errs, fmt.Errorf("%w: certificate %q expires in %d days on %s, "+
+			"which is less than %d days",
+			ErrTLSCertificateExpiring, certificate.Subject.CommonName, daysLeft,
+			certificate.NotAfter.Format(time.DateOnly), c.settings.MinDaysUntilExpiry))
+	}
+
+	if state.Version < c.settings.MinVersion {
+		errs = append(errs, fmt.Errorf("%w: %s is lower than %s",
+			ErrTLSVersionTooLow, tls.VersionName(state.Version),
+			tls.VersionName(c.settings.MinVersion)))
+	}
+
+	if c.settings.CipherSuites != nil &&
+		!slices.Contains(c.settings.CipherSuites, state.CipherSuite) {
+		errs = append(errs, fmt.Errorf("%w: %s",
+			ErrTLSCipherSuiteNotAllowed, tls.CipherSuiteName(state.CipherSuite)))
+	}
+
+	return errs
+}
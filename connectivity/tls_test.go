@@ -0,0 +1,118 @@
+package connectivity
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTLSServer(t *testing.T, maxVersion uint16) (server *httptest.Server, rootCAs *x509.CertPool) {
+	t.Helper()
+	server = httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = &tls.Config{
+		MaxVersion: maxVersion,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	rootCAs = x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+	return server, rootCAs
+}
+
+func Test_TLSChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	tls12Server, tls12RootCAs := newTLSServer(t, tls.VersionTLS12)
+	tls13Server, tls13RootCAs := newTLSServer(t, tls.VersionTLS13)
+
+	testCases := map[string]struct {
+		url         string
+		settings    TLSSettings
+		errsWrapped []error
+		errMessage  string
+	}{
+		"healthy": {
+			url: tls13Server.URL,
+			settings: TLSSettings{
+				RootCAs:            tls13RootCAs,
+				MinDaysUntilExpiry: 30,
+				MinVersion:         tls.VersionTLS13,
+				CipherSuites: []uint16{tls.TLS_AES_128_GCM_SHA256,
+					tls.TLS_AES_256_GCM_SHA384, tls.TLS_CHACHA20_POLY1305_SHA256},
+			},
+		},
+		"server name override": {
+			url: tls13Server.URL,
+			settings: TLSSettings{
+				RootCAs:    tls13RootCAs,
+				ServerName: "example.com",
+			},
+		},
+		"untrusted chain": {
+			url:         tls13Server.Listener.Addr().String(),
+			errsWrapped: []error{ErrTLSChainUntrusted},
+		},
+		"hostname mismatch": {
+			url: tls13Server.URL,
+			settings: TLSSettings{
+				RootCAs:    tls13RootCAs,
+				ServerName: "example.org",
+			},
+			errsWrapped: []error{ErrTLSHostnameMismatch},
+			errMessage: "TLS certificate hostname does not match: x509: certificate is " +
+				"valid for example.com, *.example.com, not example.org",
+		},
+		"expiring": {
+			url: tls13Server.URL,
+			settings: TLSSettings{
+				RootCAs:            tls13RootCAs,
+				MinDaysUntilExpiry: 1_000_000,
+			},
+			errsWrapped: []error{ErrTLSCertificateExpiring},
+		},
+		"version and cipher suite": {
+			url: tls12Server.URL,
+			settings: TLSSettings{
+				RootCAs:      tls12RootCAs,
+				MinVersion:   tls.VersionTLS13,
+				CipherSuites: []uint16{tls.TLS_AES_128_GCM_SHA256},
+			},
+			errsWrapped: []error{ErrTLSVersionTooLow, ErrTLSCipherSuiteNotAllowed},
+		},
+		"invalid address": {
+			url:        "127.0.0.1",
+			errMessage: "parsing address: address 127.0.0.1: missing port in address",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := NewTLSChecker(&net.Dialer{}, testCase.settings)
+
+			err := checker.Check(context.Background(), testCase.url)
+
+			if len(testCase.errsWrapped) == 0 && testCase.errMessage == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, errWrapped := range testCase.errsWrapped {
+				assert.ErrorIs(t, err, errWrapped)
+			}
+			if testCase.errMessage != "" {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package connectivity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// NewUDPChecker creates a new UDP checker sending the payload given
+// with the dialer given within the timeout given, where a zero timeout
+// means no timeout other than the context deadline. If expectedResponse
+// is nil, no response is waited for. If it is empty and not nil, any
+// response is accepted, and otherwise the response must start with it.
+func NewUDPChecker(dialer *net.Dialer, timeout time.Duration,
+	payload, expectedResponse []byte) *UDPChecker {
+	return &UDPChecker{
+		dialer:           dialer,
+		timeout:          timeout,
+		payload:          payload,
+		expectedResponse: expectedResponse,
+	}
+}
+
+// UDPChecker implements a checker to send a UDP payload and
+// optionally verify the response received.
+type UDPChecker struct {
+	dialer           *net.Dialer
+	timeout          time.Duration
+	payload          []byte
+	expectedResponse []byte
+}
+
+// ParallelChecks verifies the payload can be sent over UDP to each of
+// the addresses, given as host:port or as urls, and that the expected
+// response is received if one is set.
+// It returns a slice of errors with the same indexing and order as the
+// urls, meaning that some errors might be nil or not. You should ensure
+// to iterate over the errors and check each of them.
+func (c *UDPChecker) ParallelChecks(ctx context.Context, urls []string) (errs []error) {
+	return parallelChecks(ctx, c, urls)
+}
+
+var ErrUDPResponseUnexpected = errors.New("unexpected UDP response received")
+
+// Check verifies the payload can be sent over UDP to the address, given
+// as host:port or as an url, and that the expected response is received
+// if one is set. Note that without expected response, the check only
+// fails if the payload cannot be sent, since UDP is connectionless.
+func (c *UDPChecker) Check(ctx context.Context, url string) (err error) {
+	address, err := parseAddress("udp", url)
+	if err != nil {
+		return err
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	connection, err := c.dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return fmt.Errorf("dialing: %w", err)
+	}
+	defer connection.Close()
+
+	stop := closeOnDone(ctx, connection)
+	defer stop()
+
+	_, err = connection.Write(c.payload)
+	if err != nil {
+		return fmt.Errorf("writing payload: %w", contextOrError(ctx, err))
+	}
+
+	if c.expectedResponse == nil {
+		return nil
+	}
+
+	const maxDatagramSize = 65535
+	response := make([]byte, maxDatagramSize)
+	n, err := connection.Read(response)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", contextOrError(ctx, err))
+	}
+	response = response[:n]
+
+	if !bytes.HasPrefix(response, c.expectedResponse) {
+		return fmt.Errorf("%w: expected %q and received %q",
+			ErrUDPResponseUnexpected, c.expectedResponse, response)
+	}
+
+	return nil
+}
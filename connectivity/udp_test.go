@@ -0,0 +1,103 @@
+package connectivity
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenUDPEcho listens on a local UDP port and replies to each
+// datagram received with the response given followed by the datagram.
+// It returns the listening address.
+func listenUDPEcho(t *testing.T, response string) (address string) {
+	t.Helper()
+
+	connection, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = connection.Close()
+	})
+
+	go func() {
+		buffer := make([]byte, 512)
+		for {
+			n, address, err := connection.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			reply := append([]byte(response), buffer[:n]...)
+			_, _ = connection.WriteTo(reply, address)
+		}
+	}()
+
+	return connection.LocalAddr().String()
+}
+
+func Test_UDPChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	echoAddress := listenUDPEcho(t, "pong ")
+	silentConnection, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = silentConnection.Close()
+	})
+	silentAddress := silentConnection.LocalAddr().String()
+
+	testCases := map[string]struct {
+		url              string
+		expectedResponse []byte
+		errWrapped       error
+		errMessage       string
+	}{
+		"no response expected": {
+			url: silentAddress,
+		},
+		"any response": {
+			url:              "udp://" + echoAddress,
+			expectedResponse: []byte{},
+		},
+		"response expected": {
+			url:              echoAddress,
+			expectedResponse: []byte("pong ping"),
+		},
+		"response unexpected": {
+			url:              echoAddress,
+			expectedResponse: []byte("hello"),
+			errWrapped:       ErrUDPResponseUnexpected,
+			errMessage:       `unexpected UDP response received: expected "hello" and received "pong ping"`,
+		},
+		"response timeout": {
+			url:              silentAddress,
+			expectedResponse: []byte{},
+			errWrapped:       context.DeadlineExceeded,
+			errMessage:       "reading response: context deadline exceeded",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			const timeout = 100 * time.Millisecond
+			checker := NewUDPChecker(&net.Dialer{}, timeout,
+				[]byte("ping"), testCase.expectedResponse)
+
+			err := checker.Check(context.Background(), testCase.url)
+
+			if testCase.errMessage == "" {
+				require.NoError(t, err)
+				return
+			}
+			if testCase.errWrapped != nil {
+				assert.ErrorIs(t, err, testCase.errWrapped)
+			}
+			assert.EqualError(t, err, testCase.errMessage)
+		})
+	}
+}